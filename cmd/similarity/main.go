@@ -0,0 +1,70 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/gomodule/redigo/redis"
+
+	"github.com/stillpiercer/wikitologies/graph"
+	wikt "github.com/stillpiercer/wikitologies/wiktionary"
+)
+
+const defaultRedis = "6379"
+
+func main() {
+	lang := flag.String("lang", wikt.Russian, "language of the words")
+	strict := flag.Bool("strict", false, "strict sense matching")
+	fetches := flag.Int("fetches", 0, "maximum number of fetched words, 0 for no limit")
+	pivots := flag.String("pivots", wikt.Russian, "comma-separated languages to predict hyperonyms through")
+	overrides := flag.String("overrides", "data/overrides", "directory of the global sense overrides seeding Redis")
+	verbose := flag.Bool("v", false, "log build steps")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] word word...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() < 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if !*verbose {
+		log.SetOutput(ioutil.Discard)
+	}
+
+	pool := &redis.Pool{
+		Dial: func() (redis.Conn, error) {
+			if url, ok := os.LookupEnv("REDIS_URL"); ok {
+				return redis.DialURL(url)
+			}
+			return redis.Dial("tcp", ":"+defaultRedis)
+		},
+	}
+	defer pool.Close()
+
+	if err := graph.LoadOverrides(*overrides, pool); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	s, err := graph.Similar(flag.Args(), *lang, graph.Options{
+		Strict:     *strict,
+		MaxFetches: *fetches,
+		Pivots:     strings.Split(*pivots, ","),
+	}, pool)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("общие гиперонимы: %s\n", strings.Join(s.Common, ", "))
+	for _, p := range s.Pairs {
+		fmt.Printf("%s ~ %s: lcs=%s path=%d wup=%.4f lch=%.4f\n",
+			p.A, p.B, strings.Join(p.Common, ","), p.PathLength, p.WuPalmer, p.LeacockChodorow)
+	}
+}
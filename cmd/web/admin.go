@@ -0,0 +1,158 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/stillpiercer/wikitologies/graph"
+	wikt "github.com/stillpiercer/wikitologies/wiktionary"
+)
+
+const adminHeader = "X-Admin-Token"
+
+var adminToken string
+
+func initAdmin(r *mux.Router) {
+	adminToken = strings.TrimSpace(os.Getenv("ADMIN_TOKEN"))
+
+	s := r.PathPrefix("/admin").Subrouter()
+	s.Use(adminOnly)
+	s.HandleFunc("/cache", cacheListHandler).Methods(http.MethodGet)
+	s.HandleFunc("/cache/{title}", cacheWordHandler).Methods(http.MethodGet)
+	s.HandleFunc("/cache/{title}", cacheEvictHandler).Methods(http.MethodDelete)
+	s.HandleFunc("/cache/{title}/reparse", cacheReparseHandler).Methods(http.MethodPost)
+	s.HandleFunc("/stats", cacheStatsHandler).Methods(http.MethodGet)
+	s.HandleFunc("/overrides/{lang}", overrideListHandler).Methods(http.MethodGet)
+	s.HandleFunc("/overrides/{lang}/{title}", overrideSetHandler).Methods(http.MethodPut)
+	s.HandleFunc("/overrides/{lang}/{title}", overrideDeleteHandler).Methods(http.MethodDelete)
+}
+
+func adminOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if adminToken == "" {
+			http.Error(w, "admin endpoints are disabled", http.StatusForbidden)
+			return
+		}
+
+		token := r.Header.Get(adminHeader)
+		if token == "" {
+			token = r.URL.Query().Get("token")
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+			http.Error(w, "invalid admin token", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func cacheListHandler(w http.ResponseWriter, _ *http.Request) {
+	entries, err := graph.CachedTitles(pool)
+	panicIf(err)
+
+	writeJSON(w, entries)
+}
+
+func cacheWordHandler(w http.ResponseWriter, r *http.Request) {
+	data, err := graph.CachedWord(mux.Vars(r)["title"], pool)
+	if err == graph.ErrNotCached {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	panicIf(err)
+
+	w.Header().Set("Content-Type", JSONType)
+	_, err = w.Write(data)
+	panicIf(err)
+}
+
+func cacheEvictHandler(w http.ResponseWriter, r *http.Request) {
+	err := graph.Evict(mux.Vars(r)["title"], pool)
+	if err == graph.ErrNotCached {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	panicIf(err)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func cacheReparseHandler(w http.ResponseWriter, r *http.Request) {
+	word, err := graph.Reparse(mux.Vars(r)["title"], pool)
+	if err == wikt.ErrMissing {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	panicIf(err)
+
+	writeJSON(w, word)
+}
+
+func cacheStatsHandler(w http.ResponseWriter, _ *http.Request) {
+	stats, err := graph.Stats(pool)
+	panicIf(err)
+
+	writeJSON(w, stats)
+}
+
+func overrideListHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, graph.Overrides(mux.Vars(r)["lang"]))
+}
+
+// overrideSetHandler stores the override sent as JSON with sense, reason and author,
+// checking the sense against the current meanings of the word.
+func overrideSetHandler(w http.ResponseWriter, r *http.Request) {
+	title, lang := mux.Vars(r)["title"], mux.Vars(r)["lang"]
+
+	var o graph.Override
+	err := json.NewDecoder(r.Body).Decode(&o)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	word, err := graph.GetWord(title, pool)
+	if err == wikt.ErrMissing {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	panicIf(err)
+	if l := len(word.ByLanguage(lang)); o.Sense >= l {
+		http.Error(w, fmt.Sprintf("%s has %d meanings in %s", title, l, lang), http.StatusBadRequest)
+		return
+	}
+
+	o.Date = time.Time{}
+	err = graph.SetOverride(title, lang, o, pool)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, graph.Overrides(lang)[title])
+}
+
+func overrideDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	err := graph.DeleteOverride(mux.Vars(r)["title"], mux.Vars(r)["lang"], pool)
+	if err == graph.ErrNoOverride {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	panicIf(err)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", JSONType)
+	err := json.NewEncoder(w).Encode(v)
+	panicIf(err)
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/stillpiercer/wikitologies/graph"
+	"github.com/stillpiercer/wikitologies/parser"
+	wikt "github.com/stillpiercer/wikitologies/wiktionary"
+)
+
+const JSONType = "application/json"
+
+var errTitlesLang = errors.New("titles and lang are required")
+
+func initAPI(r *mux.Router) {
+	s := r.PathPrefix("/api").Subrouter()
+	s.HandleFunc("/words/{title}", wordHandler).Methods(http.MethodGet)
+	s.HandleFunc("/graphs/{titles}", graphHandler).Methods(http.MethodGet)
+	s.HandleFunc("/alignments/{seeds}", alignmentHandler).Methods(http.MethodGet)
+	s.HandleFunc("/similarity/{titles}", similarityHandler).Methods(http.MethodGet)
+	s.HandleFunc("/paths/{from}/{to}", pathHandler).Methods(http.MethodGet)
+	s.HandleFunc("/builds", submitHandler).Methods(http.MethodPost)
+	s.HandleFunc("/builds/{id}", jobHandler).Methods(http.MethodGet)
+	s.HandleFunc("/builds/{id}/result", jobResultHandler).Methods(http.MethodGet)
+}
+
+func wordHandler(w http.ResponseWriter, r *http.Request) {
+	if !acceptsJSON(w, r) {
+		return
+	}
+
+	title := mux.Vars(r)["title"]
+	word, err := graph.GetWord(title, pool)
+	if err == wikt.ErrMissing {
+		writeError(w, http.StatusNotFound, fmt.Errorf("%s: %v", title, err))
+		return
+	}
+	panicIf(err)
+
+	langs := parseLangs(r)
+	if len(langs) > 0 {
+		var filtered parser.Word
+		for _, l := range word {
+			if contains(langs, l.Language) {
+				filtered = append(filtered, l)
+			}
+		}
+		if len(filtered) == 0 {
+			writeError(w, http.StatusNotFound, fmt.Errorf("languages %s for %s not found", langs, title))
+			return
+		}
+		word = filtered
+	}
+
+	writeJSON(w, word)
+}
+
+func graphHandler(w http.ResponseWriter, r *http.Request) {
+	titles, lang, ok := parseAPIRequest(w, r)
+	if !ok {
+		return
+	}
+
+	opts, ok := parseAPIOptions(w, r)
+	if !ok {
+		return
+	}
+
+	t, err := graph.Build(titles, lang, opts, pool)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, t)
+}
+
+// alignmentHandler answers /api/alignments/{title}@{lang}+{title}@{lang}...
+func alignmentHandler(w http.ResponseWriter, r *http.Request) {
+	if !acceptsJSON(w, r) {
+		return
+	}
+
+	seeds := parseSeeds(r)
+	if seeds[0].Lang == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("expected {title}@{lang}+..., got %s", mux.Vars(r)["seeds"]))
+		return
+	}
+
+	opts, ok := parseAPIOptions(w, r)
+	if !ok {
+		return
+	}
+
+	t, err := graph.Align(seeds, opts, pool)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, t)
+}
+
+func similarityHandler(w http.ResponseWriter, r *http.Request) {
+	titles, lang, ok := parseAPIRequest(w, r)
+	if !ok {
+		return
+	}
+
+	opts, ok := parseAPIOptions(w, r)
+	if !ok {
+		return
+	}
+
+	s, err := graph.Similar(titles, lang, opts, pool)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, s)
+}
+
+// pathHandler answers /api/paths/{from}@{lang}/{to}[@{lang}],
+// the target word shares the language of the source one by default.
+func pathHandler(w http.ResponseWriter, r *http.Request) {
+	if !acceptsJSON(w, r) {
+		return
+	}
+
+	from := strings.Split(mux.Vars(r)["from"], "@")
+	if len(from) != 2 {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("expected {from}@{lang}, got %s", mux.Vars(r)["from"]))
+		return
+	}
+	to := strings.Split(mux.Vars(r)["to"], "@")
+	if len(to) == 1 {
+		to = append(to, from[1])
+	}
+
+	opts, ok := parseAPIOptions(w, r)
+	if !ok {
+		return
+	}
+
+	p, err := graph.FindPath(from[0], from[1], to[0], to[1], opts, pool)
+	if errors.Is(err, graph.ErrNoPath) || errors.Is(err, wikt.ErrMissing) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, p)
+}
+
+// acceptsJSON reports whether r accepts JSON, answering 406 otherwise.
+func acceptsJSON(w http.ResponseWriter, r *http.Request) bool {
+	if negotiate(r, JSONType) == "" {
+		writeError(w, http.StatusNotAcceptable, fmt.Errorf("supported content types: %s", JSONType))
+		return false
+	}
+
+	return true
+}
+
+// parseAPIRequest validates a JSON request for {titles}@{lang}.
+func parseAPIRequest(w http.ResponseWriter, r *http.Request) ([]string, string, bool) {
+	if !acceptsJSON(w, r) {
+		return nil, "", false
+	}
+
+	if !strings.Contains(mux.Vars(r)["titles"], "@") {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("expected {titles}@{lang}, got %s", mux.Vars(r)["titles"]))
+		return nil, "", false
+	}
+
+	titles, lang := parseTitlesLang(r)
+	return titles, lang, true
+}
+
+// parseAPIOptions reads the build options of r, answering 400 if they are invalid.
+func parseAPIOptions(w http.ResponseWriter, r *http.Request) (graph.Options, bool) {
+	opts, err := parseOptions(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return opts, false
+	}
+
+	return opts, true
+}
+
+func parseLangs(r *http.Request) []string {
+	var langs []string
+	for _, v := range r.URL.Query()["lang"] {
+		for _, l := range strings.Split(v, ",") {
+			if l = strings.TrimSpace(l); l != "" {
+				langs = append(langs, l)
+			}
+		}
+	}
+
+	return langs
+}
+
+// negotiate picks the offer preferred by the Accept header of r.
+// A missing header accepts the first offer, no acceptable offer yields "".
+func negotiate(r *http.Request, offers ...string) string {
+	header := r.Header.Get("Accept")
+	if header == "" {
+		return offers[0]
+	}
+
+	type accept struct {
+		mediaType string
+		q         float64
+	}
+
+	var accepts []accept
+	for _, part := range strings.Split(header, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q > 0 {
+			accepts = append(accepts, accept{mediaType: mediaType, q: q})
+		}
+	}
+	sort.SliceStable(accepts, func(i, j int) bool {
+		return accepts[i].q > accepts[j].q
+	})
+
+	for _, a := range accepts {
+		for _, offer := range offers {
+			if matches(a.mediaType, offer) {
+				return offer
+			}
+		}
+	}
+
+	return ""
+}
+
+func matches(pattern, mediaType string) bool {
+	if pattern == "*/*" || pattern == mediaType {
+		return true
+	}
+
+	if strings.HasSuffix(pattern, "/*") {
+		return strings.HasPrefix(mediaType, strings.TrimSuffix(pattern, "*"))
+	}
+
+	return false
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", JSONType)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/stillpiercer/wikitologies/graph"
+)
+
+const SVGType = "image/svg+xml"
+
+func initDiff(r *mux.Router) {
+	r.HandleFunc("/diff", diffHandler).Methods(http.MethodGet)
+	r.HandleFunc("/taxonomies/{name}/diff/{a:[0-9]+}/{b:[0-9]+}", revisionsDiffHandler).Methods(http.MethodGet)
+}
+
+// diffHandler compares the taxonomies of the view URLs a and b,
+// e.g. /diff?a=/кот@Русский&b=/кот@Русский?strict=true, answering
+// with the overlay SVG or with the JSON of the diff.
+func diffHandler(w http.ResponseWriter, r *http.Request) {
+	format := negotiate(r, SVGType, JSONType)
+	if format == "" {
+		writeError(w, http.StatusNotAcceptable, fmt.Errorf("supported content types: %s, %s", SVGType, JSONType))
+		return
+	}
+
+	var taxonomies []*graph.Taxonomy
+	for _, k := range []string{"a", "b"} {
+		rev, opts, err := parseViewURL(r.URL.Query().Get(k))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("%s: %v", k, err))
+			return
+		}
+		t, err := graph.Build(rev.Titles, rev.Lang, opts, pool)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, fmt.Errorf("%s: %v", k, err))
+			return
+		}
+		taxonomies = append(taxonomies, t)
+	}
+
+	d := graph.Compare(taxonomies[0], taxonomies[1])
+	if format == JSONType {
+		writeJSON(w, d)
+		return
+	}
+
+	data, err := renderDOT(d.DOT(), SVG)
+	panicIf(err)
+
+	w.Header().Set("Content-Type", SVGType)
+	_, err = w.Write(data)
+	panicIf(err)
+}
+
+// revisionsDiffHandler compares the revisions {a} and {b} of a saved taxonomy.
+func revisionsDiffHandler(w http.ResponseWriter, r *http.Request) {
+	s, err := graph.GetSaved(mux.Vars(r)["name"], pool)
+	if err == graph.ErrNoTaxonomy {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	panicIf(err)
+
+	query := url.Values{}
+	for _, k := range []string{"a", "b"} {
+		n, _ := strconv.Atoi(mux.Vars(r)[k])
+		rev, err := s.Revision(n)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		query.Set(k, revisionURL(s.Name, rev))
+	}
+
+	http.Redirect(w, r, "/diff?"+query.Encode(), http.StatusFound)
+}
+
+// parseViewURL reads the words and build options of the view URL raw.
+func parseViewURL(raw string) (*graph.Revision, graph.Options, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, graph.Options{}, err
+	}
+	rev, ok := parseRevision(u)
+	if !ok {
+		return nil, graph.Options{}, fmt.Errorf("expected a view URL /{titles}@{lang}, got %q", raw)
+	}
+
+	opts, err := queryOptions(u)
+	return rev, opts, err
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/stillpiercer/wikitologies/graph"
+)
+
+const (
+	defaultWorkers = 2
+
+	pollTimeout = 5 * time.Second
+)
+
+// startWorkers runs BUILD_WORKERS workers taking build jobs off the queue.
+func startWorkers() {
+	n, err := strconv.Atoi(os.Getenv("BUILD_WORKERS"))
+	if err != nil || n <= 0 {
+		n = defaultWorkers
+	}
+
+	for i := 0; i < n; i++ {
+		go work()
+	}
+	log.Println("build workers:", n)
+}
+
+func work() {
+	for {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Println("build worker:", r)
+					time.Sleep(pollTimeout)
+				}
+			}()
+
+			if err := graph.Work(pollTimeout, pool); err != nil {
+				log.Println("build worker:", err)
+				time.Sleep(pollTimeout)
+			}
+		}()
+	}
+}
+
+// submitHandler queues the build described by the JSON body,
+// answering 202 with the job and its location.
+func submitHandler(w http.ResponseWriter, r *http.Request) {
+	if !acceptsJSON(w, r) {
+		return
+	}
+
+	var req graph.BuildRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if len(req.Titles) == 0 || req.Lang == "" {
+		writeError(w, http.StatusBadRequest, errTitlesLang)
+		return
+	}
+
+	job, err := graph.Submit(req, pool)
+	panicIf(err)
+
+	w.Header().Set("Location", "/api/builds/"+job.ID)
+	w.Header().Set("Content-Type", JSONType)
+	w.WriteHeader(http.StatusAccepted)
+	err = json.NewEncoder(w).Encode(job)
+	panicIf(err)
+}
+
+func jobHandler(w http.ResponseWriter, r *http.Request) {
+	if !acceptsJSON(w, r) {
+		return
+	}
+
+	job, err := graph.GetJob(mux.Vars(r)["id"], pool)
+	if err == graph.ErrNoJob {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	panicIf(err)
+
+	writeJSON(w, job)
+}
+
+func jobResultHandler(w http.ResponseWriter, r *http.Request) {
+	if !acceptsJSON(w, r) {
+		return
+	}
+
+	data, err := graph.JobResult(mux.Vars(r)["id"], pool)
+	if err == graph.ErrNoJob {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	panicIf(err)
+
+	w.Header().Set("Content-Type", JSONType)
+	_, err = w.Write(data)
+	panicIf(err)
+}
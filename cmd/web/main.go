@@ -2,11 +2,13 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"html/template"
 	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
 	"strconv"
@@ -22,15 +24,23 @@ import (
 const (
 	SVG = "svg"
 	DOT = "dot"
+	// GraphML, GEXF and Cytoscape are written natively, other formats by dot -T.
+	GraphML   = "graphml"
+	GEXF      = "gexf"
+	Cytoscape = "cyjs"
+	// Turtle and JSONLD export SKOS concept schemes, see skosIRIs.
+	Turtle = "ttl"
+	JSONLD = "jsonld"
 
 	defaultPort  = "8080"
 	defaultRedis = "6379"
 )
 
 var (
-	mainTemplate *template.Template
-	viewTemplate *template.Template
-	editTemplate *template.Template
+	mainTemplate  *template.Template
+	viewTemplate  *template.Template
+	editTemplate  *template.Template
+	savedTemplate *template.Template
 
 	pool *redis.Pool
 )
@@ -42,18 +52,28 @@ func main() {
 	wd, err := os.Getwd()
 	panicIf(err)
 
+	err = graph.LoadOverrides(wd+"/data/overrides", pool)
+	panicIf(err)
+	// Queued builds pick their senses with the overrides.
+	startWorkers()
+
 	mainTemplate = template.Must(template.ParseFiles(wd + "/templates/main.html"))
-	viewTemplate = template.Must(template.
-		New("view.html").
-		Funcs(template.FuncMap{"draw": draw}).
-		ParseFiles(wd + "/templates/view.html"))
+	viewTemplate = template.Must(template.ParseFiles(wd + "/templates/view.html"))
 	editTemplate = template.Must(template.ParseFiles(wd + "/templates/edit.html"))
+	savedTemplate = template.Must(template.ParseFiles(wd + "/templates/saved.html"))
 
 	r := mux.NewRouter()
+	initAdmin(r)
+	initAPI(r)
+	initStream(r)
+	initSaved(r)
+	initDiff(r)
 	r.HandleFunc("/", mainHandler)
 	r.HandleFunc("/{titles}", viewHandler)
+	r.HandleFunc("/align/{seeds}", alignHandler)
 	r.HandleFunc("/edit/{title}", editHandler)
 	r.HandleFunc("/save/{format}/{titles}", saveHandler)
+	r.HandleFunc("/save/{format}/align/{seeds}", saveAlignedHandler)
 
 	port, ok := os.LookupEnv("PORT")
 	if !ok {
@@ -88,19 +108,37 @@ func mainHandler(w http.ResponseWriter, _ *http.Request) {
 }
 
 func viewHandler(w http.ResponseWriter, r *http.Request) {
+	if recordEdit(w, r) {
+		return
+	}
+
 	titles, lang := parseTitlesLang(r)
-	strict, params := parseStrictParams(r)
 
 	data := struct {
 		Titles []string
 		Lang   string
-		Strict bool
-		Params map[string]int
 	}{
 		Titles: titles,
 		Lang:   lang,
-		Strict: strict,
-		Params: params,
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	err := viewTemplate.Execute(w, data)
+	panicIf(err)
+}
+
+// alignHandler shows the taxonomies of /align/{title}@{lang}+{title}@{lang}... side by side.
+func alignHandler(w http.ResponseWriter, r *http.Request) {
+	var titles []string
+	for _, s := range parseSeeds(r) {
+		titles = append(titles, s.String())
+	}
+
+	data := struct {
+		Titles []string
+		Lang   string
+	}{
+		Titles: titles,
 	}
 
 	w.Header().Set("Content-Type", "text/html")
@@ -115,6 +153,9 @@ func editHandler(w http.ResponseWriter, r *http.Request) {
 	if strings.Contains(title, "->") {
 		title = strings.Split(title, "->")[1]
 	}
+	if strings.Contains(title, "<-") {
+		title = strings.Split(title, "<-")[1]
+	}
 
 	word, err := graph.GetWord(title, pool)
 	if err != nil {
@@ -145,10 +186,21 @@ func editHandler(w http.ResponseWriter, r *http.Request) {
 
 func saveHandler(w http.ResponseWriter, r *http.Request) {
 	titles, lang := parseTitlesLang(r)
-	strict, params := parseStrictParams(r)
 	format := mux.Vars(r)["format"]
 
-	data, err := dot(titles, lang, strict, params, format)
+	opts, err := parseOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	iris, ok := parseIRIs(w, r, format)
+	if !ok {
+		return
+	}
+
+	t, err := graph.Build(titles, lang, opts, pool)
+	panicIf(err)
+	data, err := export(t, format, iris)
 	panicIf(err)
 
 	filename := fmt.Sprintf("attachment; filename=%s.%s", strings.Join(titles, "+"), format)
@@ -158,53 +210,227 @@ func saveHandler(w http.ResponseWriter, r *http.Request) {
 	panicIf(err)
 }
 
+func saveAlignedHandler(w http.ResponseWriter, r *http.Request) {
+	seeds := parseSeeds(r)
+	format := mux.Vars(r)["format"]
+
+	opts, err := parseOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	iris, ok := parseIRIs(w, r, format)
+	if !ok {
+		return
+	}
+
+	t, err := graph.Align(seeds, opts, pool)
+	panicIf(err)
+	data, err := export(t, format, iris)
+	panicIf(err)
+
+	filename := fmt.Sprintf("attachment; filename=%s.%s", mux.Vars(r)["seeds"], format)
+	w.Header().Set("Content-Disposition", filename)
+	w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+	_, err = io.Copy(w, bytes.NewReader(data))
+	panicIf(err)
+}
+
+// parseSeeds splits {title}@{lang}+{title}@{lang}...,
+// words without a language share the one of the previous word.
+func parseSeeds(r *http.Request) []graph.Seed {
+	var seeds []graph.Seed
+	var lang string
+	for _, s := range strings.Split(mux.Vars(r)["seeds"], "+") {
+		split := strings.SplitN(s, "@", 2)
+		if len(split) == 2 {
+			lang = split[1]
+		}
+		seeds = append(seeds, graph.Seed{Title: split[0], Lang: lang})
+	}
+
+	return seeds
+}
+
 func parseTitlesLang(r *http.Request) ([]string, string) {
 	split := strings.Split(mux.Vars(r)["titles"], "@")
 	return strings.Split(split[0], "+"), split[1]
 }
 
-func parseStrictParams(r *http.Request) (bool, map[string]int) {
-	var strict bool
-	if r.URL.Query().Get("strict") == "true" {
-		strict = true
+func parseOptions(r *http.Request) (graph.Options, error) {
+	return queryOptions(r.URL)
+}
+
+// queryOptions reads the build options off the query of a view URL u,
+// failing on unknown selectors.
+func queryOptions(u *url.URL) (graph.Options, error) {
+	var opts graph.Options
+	if u.Query().Get("strict") == "true" {
+		opts.Strict = true
+	}
+	if u.Query().Get("synsets") == "true" {
+		opts.Synsets = true
+	}
+	if u.Query().Get("cycles") == "break" {
+		opts.BreakCycles = true
+	}
+	if u.Query().Get("reduce") == "true" {
+		opts.Reduce = true
+	}
+
+	switch dir := graph.Direction(u.Query().Get("direction")); dir {
+	case graph.Up, graph.Down, graph.Both:
+		opts.Direction = dir
+	default:
+		opts.Direction = graph.Up
+	}
+
+	if v, err := strconv.ParseFloat(u.Query().Get("confidence"), 64); err == nil {
+		opts.MinConfidence = v
 	}
 
-	params := make(map[string]int)
-	for k, v := range r.URL.Query() {
+	opts.Presets = make(map[string]int)
+	for k, v := range u.Query() {
 		last := len(v) - 1
 		value, err := strconv.Atoi(v[last])
 		if err != nil {
 			continue
 		}
-		params[k] = value
+
+		switch k {
+		case "confidence":
+		case "depth":
+			opts.MaxDepth = value
+		case "nodes":
+			opts.MaxNodes = value
+		case "fetches":
+			opts.MaxFetches = value
+		case string(graph.Synonymy), string(graph.Antonymy), string(graph.Meronymy), string(graph.Holonymy), string(graph.Translation):
+			if opts.Relations == nil {
+				opts.Relations = make(map[graph.Relation]int)
+			}
+			opts.Relations[graph.Relation(k)] = value
+		default:
+			opts.Presets[k] = value
+		}
+	}
+
+	if v := u.Query().Get("pivots"); v != "" {
+		opts.Pivots = strings.Split(v, ",")
+	}
+	if v := u.Query().Get("selectors"); v != "" {
+		chain, err := graph.ParseSelectors(strings.Split(v, ","), opts.Presets)
+		if err != nil {
+			return opts, err
+		}
+		opts.Selectors = chain
+	}
+	if name := u.Query().Get(taxonomyParam); name != "" {
+		patch, err := graph.GetPatch(name, pool)
+		panicIf(err)
+		opts.Patch = patch
 	}
 
-	return strict, params
+	return opts, nil
 }
 
-func dot(titles []string, lang string, strict bool, params map[string]int, format string) ([]byte, error) {
-	g, err := graph.Build(titles, lang, strict, params, pool)
-	if err != nil {
-		return nil, err
+// export renders t in format, SKOS formats identify concepts by iris.
+func export(t *graph.Taxonomy, format string, iris graph.IRIs) ([]byte, error) {
+	switch format {
+	case Turtle:
+		return t.Turtle(iris)
+	case JSONLD:
+		return t.JSONLD(iris)
+	}
+
+	return render(t, format)
+}
+
+// parseIRIs returns the IRIs of the SKOS export r, answering 400
+// if they are not absolute. Other formats need none.
+func parseIRIs(w http.ResponseWriter, r *http.Request, format string) (graph.IRIs, bool) {
+	if format != Turtle && format != JSONLD {
+		return graph.IRIs{}, true
+	}
+
+	iris := skosIRIs(r)
+	if err := iris.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return iris, false
+	}
+
+	return iris, true
+}
+
+// skosIRIs prefixes concepts with the base query param, SKOS_BASE or
+// /concepts/ of the host in that order. The scheme is the view saved by r
+// unless the scheme query param is given.
+func skosIRIs(r *http.Request) graph.IRIs {
+	proto := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		proto = "https"
+	}
+	host := proto + "://" + r.Host
+
+	base := r.URL.Query().Get("base")
+	if base == "" {
+		base = os.Getenv("SKOS_BASE")
+	}
+	if base == "" {
+		base = host + "/concepts/"
+	}
+
+	scheme := r.URL.Query().Get("scheme")
+	if scheme == "" {
+		view := url.URL{
+			Path:     strings.TrimPrefix(r.URL.Path, "/save/"+mux.Vars(r)["format"]),
+			RawQuery: r.URL.Query().Encode(),
+		}
+		scheme = host + view.String()
+	}
+
+	return graph.IRIs{Concepts: base, Scheme: scheme}
+}
+
+func render(t *graph.Taxonomy, format string) ([]byte, error) {
+	switch format {
+	case GraphML:
+		return t.GraphML()
+	case GEXF:
+		return t.GEXF()
+	case Cytoscape:
+		return t.Cytoscape()
 	}
 
+	return renderDOT(t.DOT(false), format)
+}
+
+// renderDOT lays out the graphviz source src with dot -T{format}.
+func renderDOT(src, format string) ([]byte, error) {
 	if format == DOT {
-		return []byte(g.String()), nil
+		return []byte(src), nil
 	}
 
 	cmd := exec.Command("dot", "-T"+format)
-	cmd.Stdin = strings.NewReader(g.String())
+	cmd.Stdin = strings.NewReader(src)
 
 	return cmd.Output()
 }
 
-func draw(titles []string, lang string, strict bool, params map[string]int) template.HTML {
-	data, err := dot(titles, lang, strict, params, SVG)
+// embed renders t as SVG followed by its trace for the view page scripts,
+// redundant edges included for the page to toggle.
+func embed(t *graph.Taxonomy) template.HTML {
+	data, err := renderDOT(t.DOT(true), SVG)
+	if err != nil {
+		return template.HTML(err.Error())
+	}
+
+	trace, err := json.Marshal(t.Trace)
 	if err != nil {
 		return template.HTML(err.Error())
 	}
 
-	return template.HTML(data)
+	return template.HTML(fmt.Sprintf(`%s<script id="trace" type="application/json">%s</script>`, data, trace))
 }
 
 func recovery(handler http.Handler) http.Handler {
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/stillpiercer/wikitologies/graph"
+)
+
+// taxonomyParam marks the view URLs of saved taxonomies,
+// sense changes posted to them become revisions.
+const taxonomyParam = "taxonomy"
+
+func initSaved(r *mux.Router) {
+	r.HandleFunc("/taxonomies", savedListHandler).Methods(http.MethodGet)
+	r.HandleFunc("/taxonomies", saveTaxonomyHandler).Methods(http.MethodPost)
+	r.HandleFunc("/taxonomies/{name}", openSavedHandler).Methods(http.MethodGet)
+	r.HandleFunc("/taxonomies/{name}/{n:[0-9]+}", openSavedHandler).Methods(http.MethodGet)
+	r.HandleFunc("/taxonomies/{name}/{n:[0-9]+}/restore", restoreHandler).Methods(http.MethodPost)
+
+	s := r.PathPrefix("/api/taxonomies").Subrouter()
+	s.HandleFunc("", apiSavedListHandler).Methods(http.MethodGet)
+	s.HandleFunc("/{name}", apiSavedHandler).Methods(http.MethodGet)
+	s.HandleFunc("/{name}", apiSaveHandler).Methods(http.MethodPost)
+	s.HandleFunc("/{name}/revisions/{n:[0-9]+}/restore", apiRestoreHandler).Methods(http.MethodPost)
+	s.HandleFunc("/{name}/diff/{a:[0-9]+}/{b:[0-9]+}", apiDiffHandler).Methods(http.MethodGet)
+	s.HandleFunc("/{name}/patch", patchHandler).Methods(http.MethodGet)
+	s.HandleFunc("/{name}/patch", addPatchHandler).Methods(http.MethodPost)
+	s.HandleFunc("/{name}/patch/{n:[0-9]+}", removePatchHandler).Methods(http.MethodDelete)
+}
+
+func savedListHandler(w http.ResponseWriter, _ *http.Request) {
+	summaries, err := graph.ListSaved(pool)
+	panicIf(err)
+
+	var saved []*graph.Saved
+	for _, s := range summaries {
+		taxonomy, err := graph.GetSaved(s.Name, pool)
+		panicIf(err)
+		saved = append(saved, taxonomy)
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	err = savedTemplate.Execute(w, saved)
+	panicIf(err)
+}
+
+// saveTaxonomyHandler saves the view at the url form value under the name one.
+func saveTaxonomyHandler(w http.ResponseWriter, r *http.Request) {
+	u, err := url.Parse(r.FormValue("url"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rev, ok := parseRevision(u)
+	if !ok {
+		http.Error(w, "expected a view URL /{titles}@{lang}", http.StatusBadRequest)
+		return
+	}
+
+	name := strings.TrimSpace(r.FormValue("name"))
+	rev, err = graph.Save(name, rev, pool)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	http.Redirect(w, r, revisionURL(name, rev), http.StatusSeeOther)
+}
+
+// openSavedHandler redirects to the view of a revision, the current one by default.
+func openSavedHandler(w http.ResponseWriter, r *http.Request) {
+	rev, ok := findRevision(w, r)
+	if !ok {
+		return
+	}
+
+	http.Redirect(w, r, revisionURL(mux.Vars(r)["name"], rev), http.StatusFound)
+}
+
+func restoreHandler(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	n, _ := strconv.Atoi(mux.Vars(r)["n"])
+
+	_, err := graph.Restore(name, n, pool)
+	if err == graph.ErrNoTaxonomy || err == graph.ErrNoRevision {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	panicIf(err)
+
+	http.Redirect(w, r, "/taxonomies", http.StatusSeeOther)
+}
+
+// recordEdit saves the sense change posted to the view of a saved taxonomy
+// as its next revision and redirects to the view of the revision.
+func recordEdit(w http.ResponseWriter, r *http.Request) bool {
+	name := r.URL.Query().Get(taxonomyParam)
+	if name == "" || r.Method != http.MethodPost {
+		return false
+	}
+
+	rev, ok := parseRevision(r.URL)
+	if !ok {
+		return false
+	}
+	rev, err := graph.Save(name, rev, pool)
+	panicIf(err)
+
+	http.Redirect(w, r, revisionURL(name, rev), http.StatusSeeOther)
+	return true
+}
+
+func apiSavedListHandler(w http.ResponseWriter, r *http.Request) {
+	if !acceptsJSON(w, r) {
+		return
+	}
+
+	summaries, err := graph.ListSaved(pool)
+	panicIf(err)
+
+	writeJSON(w, summaries)
+}
+
+func apiSavedHandler(w http.ResponseWriter, r *http.Request) {
+	if !acceptsJSON(w, r) {
+		return
+	}
+
+	s, err := graph.GetSaved(mux.Vars(r)["name"], pool)
+	if err == graph.ErrNoTaxonomy {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	panicIf(err)
+
+	writeJSON(w, s)
+}
+
+// apiSaveHandler records the revision sent as JSON with titles, lang and params.
+func apiSaveHandler(w http.ResponseWriter, r *http.Request) {
+	if !acceptsJSON(w, r) {
+		return
+	}
+
+	var rev graph.Revision
+	err := json.NewDecoder(r.Body).Decode(&rev)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if len(rev.Titles) == 0 || rev.Lang == "" {
+		writeError(w, http.StatusBadRequest, errTitlesLang)
+		return
+	}
+
+	saved, err := graph.Save(mux.Vars(r)["name"], &rev, pool)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	writeJSON(w, saved)
+}
+
+func apiRestoreHandler(w http.ResponseWriter, r *http.Request) {
+	if !acceptsJSON(w, r) {
+		return
+	}
+
+	n, _ := strconv.Atoi(mux.Vars(r)["n"])
+	rev, err := graph.Restore(mux.Vars(r)["name"], n, pool)
+	if err == graph.ErrNoTaxonomy || err == graph.ErrNoRevision {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	panicIf(err)
+
+	writeJSON(w, rev)
+}
+
+func apiDiffHandler(w http.ResponseWriter, r *http.Request) {
+	if !acceptsJSON(w, r) {
+		return
+	}
+
+	s, err := graph.GetSaved(mux.Vars(r)["name"], pool)
+	if err == graph.ErrNoTaxonomy {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	panicIf(err)
+
+	a, _ := strconv.Atoi(mux.Vars(r)["a"])
+	b, _ := strconv.Atoi(mux.Vars(r)["b"])
+	ra, err := s.Revision(a)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	rb, err := s.Revision(b)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	writeJSON(w, graph.DiffRevisions(ra, rb))
+}
+
+func patchHandler(w http.ResponseWriter, r *http.Request) {
+	if !acceptsJSON(w, r) {
+		return
+	}
+
+	p, err := graph.GetPatch(mux.Vars(r)["name"], pool)
+	panicIf(err)
+
+	writeJSON(w, p)
+}
+
+// addPatchHandler appends the patch entry sent as JSON to the patch of
+// a saved taxonomy, its views apply the patch from then on.
+func addPatchHandler(w http.ResponseWriter, r *http.Request) {
+	if !acceptsJSON(w, r) {
+		return
+	}
+
+	name := mux.Vars(r)["name"]
+	_, err := graph.GetSaved(name, pool)
+	if err == graph.ErrNoTaxonomy {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	panicIf(err)
+
+	var e graph.PatchEntry
+	err = json.NewDecoder(r.Body).Decode(&e)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = graph.AddPatch(name, &e, pool)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", JSONType)
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(e)
+	panicIf(err)
+}
+
+func removePatchHandler(w http.ResponseWriter, r *http.Request) {
+	n, _ := strconv.Atoi(mux.Vars(r)["n"])
+	err := graph.RemovePatch(mux.Vars(r)["name"], n, pool)
+	if err == graph.ErrNoPatch {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	panicIf(err)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// findRevision looks up the revision {n} of the taxonomy {name},
+// the current one without {n}, answering 404 when missing.
+func findRevision(w http.ResponseWriter, r *http.Request) (*graph.Revision, bool) {
+	s, err := graph.GetSaved(mux.Vars(r)["name"], pool)
+	if err == graph.ErrNoTaxonomy {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return nil, false
+	}
+	panicIf(err)
+
+	n, ok := mux.Vars(r)["n"]
+	if !ok {
+		return s.Current(), true
+	}
+
+	i, _ := strconv.Atoi(n)
+	rev, err := s.Revision(i)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return nil, false
+	}
+
+	return rev, true
+}
+
+// parseRevision reads a revision off a view URL /{titles}@{lang}?{params},
+// repeated params keep their last value like in parseOptions.
+func parseRevision(u *url.URL) (*graph.Revision, bool) {
+	split := strings.Split(strings.TrimPrefix(u.Path, "/"), "@")
+	if len(split) != 2 || split[0] == "" || strings.Contains(split[1], "/") {
+		return nil, false
+	}
+
+	params := make(map[string]string)
+	for k, v := range u.Query() {
+		if k != taxonomyParam {
+			params[k] = v[len(v)-1]
+		}
+	}
+
+	return &graph.Revision{
+		Titles: strings.Split(split[0], "+"),
+		Lang:   split[1],
+		Params: params,
+	}, true
+}
+
+func revisionURL(name string, rev *graph.Revision) string {
+	query := url.Values{taxonomyParam: {name}}
+	for k, v := range rev.Params {
+		query.Set(k, v)
+	}
+
+	u := url.URL{
+		Path:     "/" + strings.Join(rev.Titles, "+") + "@" + rev.Lang,
+		RawQuery: query.Encode(),
+	}
+	return u.String()
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/stillpiercer/wikitologies/graph"
+)
+
+const EventStreamType = "text/event-stream"
+
+func initStream(r *mux.Router) {
+	s := r.PathPrefix("/stream").Subrouter()
+	s.HandleFunc("/align/{seeds}", func(w http.ResponseWriter, r *http.Request) {
+		seeds := parseSeeds(r)
+		stream(w, r, func(opts graph.Options) (*graph.Taxonomy, error) {
+			return graph.Align(seeds, opts, pool)
+		})
+	})
+	s.HandleFunc("/{titles}", func(w http.ResponseWriter, r *http.Request) {
+		titles, lang := parseTitlesLang(r)
+		stream(w, r, func(opts graph.Options) (*graph.Taxonomy, error) {
+			return graph.Build(titles, lang, opts, pool)
+		})
+	})
+}
+
+// stream runs build sending its progress as server-sent events named after
+// graph actions, the build ends with either a done event carrying the SVG
+// of the graph followed by its trace or a failed one carrying the error.
+func stream(w http.ResponseWriter, r *http.Request, build func(graph.Options) (*graph.Taxonomy, error)) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	opts, err := parseOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", EventStreamType)
+	w.Header().Set("Cache-Control", "no-cache")
+
+	events := make(chan *graph.Event, 64)
+	result := make(chan []byte, 1)
+	failure := make(chan error, 1)
+
+	opts.Progress = func(e *graph.Event) {
+		select {
+		case events <- e:
+		case <-r.Context().Done():
+		}
+	}
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				failure <- fmt.Errorf("%v", r)
+			}
+		}()
+
+		t, err := build(opts)
+		if err != nil {
+			failure <- err
+			return
+		}
+
+		data, err := json.Marshal(string(embed(t)))
+		if err != nil {
+			failure <- err
+			return
+		}
+		result <- data
+	}()
+
+	for {
+		select {
+		case e := <-events:
+			sendEvent(w, e)
+		case data := <-result:
+			for len(events) > 0 {
+				sendEvent(w, <-events)
+			}
+			send(w, "done", data)
+			flusher.Flush()
+			return
+		case err := <-failure:
+			data, _ := json.Marshal(err.Error())
+			send(w, "failed", data)
+			flusher.Flush()
+			return
+		case <-r.Context().Done():
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+func sendEvent(w http.ResponseWriter, e *graph.Event) {
+	data, err := json.Marshal(e)
+	panicIf(err)
+	send(w, string(e.Action), data)
+}
+
+func send(w http.ResponseWriter, event string, data []byte) {
+	_, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+	panicIf(err)
+}
@@ -0,0 +1,139 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// Seed is a word starting a build in its language.
+type Seed struct {
+	Title string `json:"title"`
+	Lang  string `json:"lang"`
+}
+
+func (s Seed) String() string {
+	return s.Title + "@" + s.Lang
+}
+
+// Align builds the hyperonym graph of every language of seeds separately
+// and links the senses translating each other. Node IDs of the result get
+// the language suffix, e.g. кот@Русский, as the graphs may share words.
+func Align(seeds []Seed, opts Options, pool *redis.Pool) (*Taxonomy, error) {
+	var langs []string
+	titles := make(map[string][]string)
+	for _, s := range seeds {
+		if titles[s.Lang] == nil {
+			langs = append(langs, s.Lang)
+		}
+		titles[s.Lang] = append(titles[s.Lang], s.Title)
+	}
+
+	var names []string
+	for _, s := range seeds {
+		names = append(names, s.String())
+	}
+	t := NewTaxonomy(strings.Join(names, " + "), "")
+	t.Langs = langs
+
+	builders := make([]*builder, len(langs))
+	for i, lang := range langs {
+		b, err := build(titles[lang], lang, opts, pool)
+		if err != nil {
+			return nil, err
+		}
+		builders[i] = b
+		t.add(b.g)
+	}
+
+	for i, a := range builders {
+		for _, b := range builders[i+1:] {
+			t.align(a, b)
+		}
+	}
+
+	return t, nil
+}
+
+// add copies g into the aligned taxonomy t renaming its nodes.
+func (t *Taxonomy) add(g *Taxonomy) {
+	id := func(name string) string {
+		return name + "@" + g.Lang
+	}
+	edge := func(e *Edge) *Edge {
+		c := *e
+		c.From, c.To = id(e.From), id(e.To)
+		return &c
+	}
+
+	for _, n := range g.Nodes {
+		c := *n
+		c.ID = id(n.ID)
+		t.AddNode(&c)
+	}
+	for _, e := range g.Edges {
+		t.AddEdge(edge(e))
+	}
+	for _, e := range g.Broken {
+		t.Broken = append(t.Broken, edge(e))
+	}
+	for _, e := range g.Redundant {
+		t.Redundant = append(t.Redundant, edge(e))
+	}
+	for _, cycle := range g.Cycles {
+		var c []string
+		for _, name := range cycle {
+			c = append(c, id(name))
+		}
+		t.Cycles = append(t.Cycles, c)
+	}
+	t.Truncated = t.Truncated || g.Truncated
+	t.Trace = append(t.Trace, g.Trace...)
+}
+
+// align links the nodes of a and b whose senses list each other among
+// translations: mutual links are certain, one-way ones are denied in strict mode.
+func (t *Taxonomy) align(a, b *builder) {
+	for _, na := range a.g.Nodes {
+		ma := a.senses[na.ID]
+		for _, nb := range b.g.Nodes {
+			mb := b.senses[nb.ID]
+			if ma == nil || mb == nil {
+				continue
+			}
+
+			forward := containsAny(ma.Translations.ByLanguage(b.lang), b.lemmas(nb.ID))
+			backward := containsAny(mb.Translations.ByLanguage(a.lang), a.lemmas(na.ID))
+			if !forward && !backward {
+				continue
+			}
+
+			tooltip := fmt.Sprintf("%s-%s->%s", na.ID, Translation, nb.ID)
+			score := ConfidenceBackLink
+			if !forward || !backward {
+				if a.opts.Strict {
+					t.trace(tooltip, nb.Title, Denied, "translation is not mutual")
+					continue
+				}
+				score = ConfidencePolysemous
+			}
+			if score < a.opts.MinConfidence {
+				continue
+			}
+
+			t.AddEdge(&Edge{
+				From:     na.ID + "@" + a.lang,
+				To:       nb.ID + "@" + b.lang,
+				Relation: Translation,
+				Kind:     Own,
+				Provenance: Provenance{
+					Tooltip:    tooltip,
+					Strict:     a.opts.Strict,
+					Confidence: score,
+				},
+			})
+			t.trace(tooltip, nb.Title, Added, "aligned, confidence %.2f", score)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package graph
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+
+	"github.com/stillpiercer/wikitologies/parser"
+	wikt "github.com/stillpiercer/wikitologies/wiktionary"
+)
+
+const (
+	datePrefix    = "date:"
+	wordPrefix    = "word:"
+	versionPrefix = "version:"
+
+	hitsKey   = "stats:hits"
+	missesKey = "stats:misses"
+)
+
+var ErrNotCached = errors.New("word is not cached")
+
+type CacheEntry struct {
+	Title    string `json:"title"`
+	Revision string `json:"revision"`
+}
+
+type CacheStats struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+}
+
+func GetWord(title string, pool *redis.Pool) (parser.Word, error) {
+	c := pool.Get()
+	defer c.Close()
+
+	dateWikiStr, err := wikt.GetLastRevision(title)
+	if err != nil {
+		return nil, err
+	}
+
+	dateRedisStr, err := redis.String(c.Do("GET", datePrefix+title))
+	if err != nil {
+		if err == redis.ErrNil {
+			return miss(c, title, dateWikiStr)
+		}
+		return nil, err
+	}
+
+	dateWiki, err := time.Parse(time.RFC3339, dateWikiStr)
+	if err != nil {
+		return nil, err
+	}
+
+	dateRedis, err := time.Parse(time.RFC3339, dateRedisStr)
+	if err != nil {
+		return nil, err
+	}
+
+	if dateWiki.Sub(dateRedis) > 0 {
+		return miss(c, title, dateWikiStr)
+	}
+
+	// Words parsed by an older parser lack the data added since.
+	version, err := redis.Int(c.Do("GET", versionPrefix+title))
+	if err != nil && err != redis.ErrNil {
+		return nil, err
+	}
+	if version != parser.Version {
+		return miss(c, title, dateWikiStr)
+	}
+
+	s, err := redis.String(c.Do("GET", wordPrefix+title))
+	if err != nil {
+		if err == redis.ErrNil {
+			return miss(c, title, dateWikiStr)
+		}
+		return nil, err
+	}
+
+	word := parser.Word{}
+	err = json.Unmarshal([]byte(s), &word)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = c.Do("INCR", hitsKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return word, nil
+}
+
+func CachedTitles(pool *redis.Pool) ([]CacheEntry, error) {
+	c := pool.Get()
+	defer c.Close()
+
+	var keys []string
+	cursor := 0
+	for {
+		values, err := redis.Values(c.Do("SCAN", cursor, "MATCH", datePrefix+"*", "COUNT", 1000))
+		if err != nil {
+			return nil, err
+		}
+
+		cursor, err = redis.Int(values[0], nil)
+		if err != nil {
+			return nil, err
+		}
+
+		batch, err := redis.Strings(values[1], nil)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, batch...)
+
+		if cursor == 0 {
+			break
+		}
+	}
+	sort.Strings(keys)
+
+	entries := make([]CacheEntry, 0, len(keys))
+	for _, key := range keys {
+		date, err := redis.String(c.Do("GET", key))
+		if err != nil {
+			if err == redis.ErrNil {
+				continue
+			}
+			return nil, err
+		}
+
+		entries = append(entries, CacheEntry{
+			Title:    strings.TrimPrefix(key, datePrefix),
+			Revision: date,
+		})
+	}
+
+	return entries, nil
+}
+
+func CachedWord(title string, pool *redis.Pool) ([]byte, error) {
+	c := pool.Get()
+	defer c.Close()
+
+	data, err := redis.Bytes(c.Do("GET", wordPrefix+title))
+	if err != nil {
+		if err == redis.ErrNil {
+			return nil, ErrNotCached
+		}
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func Reparse(title string, pool *redis.Pool) (parser.Word, error) {
+	c := pool.Get()
+	defer c.Close()
+
+	date, err := wikt.GetLastRevision(title)
+	if err != nil {
+		return nil, err
+	}
+
+	return update(c, title, date)
+}
+
+func Evict(title string, pool *redis.Pool) error {
+	c := pool.Get()
+	defer c.Close()
+
+	n, err := redis.Int(c.Do("DEL", datePrefix+title, wordPrefix+title, versionPrefix+title))
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotCached
+	}
+
+	return nil
+}
+
+func Stats(pool *redis.Pool) (CacheStats, error) {
+	c := pool.Get()
+	defer c.Close()
+
+	var stats CacheStats
+	values, err := redis.Values(c.Do("MGET", hitsKey, missesKey))
+	if err != nil {
+		return stats, err
+	}
+
+	_, err = redis.Scan(values, &stats.Hits, &stats.Misses)
+	return stats, err
+}
+
+func miss(c redis.Conn, title, date string) (parser.Word, error) {
+	_, err := c.Do("INCR", missesKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return update(c, title, date)
+}
+
+func update(c redis.Conn, title, date string) (parser.Word, error) {
+	word, err := parser.Parse(title)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(word)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = c.Do("SET", datePrefix+title, date)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = c.Do("SET", wordPrefix+title, data)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = c.Do("SET", versionPrefix+title, parser.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	return word, nil
+}
@@ -0,0 +1,107 @@
+package graph
+
+import "strings"
+
+// Confidence of edges by derivation: own edges confirmed by a back-link
+// of the target sense, own edges to a monosemous or a polysemous word,
+// predicted edges agreed by every pivot translation.
+const (
+	ConfidenceBackLink   = 1.0
+	ConfidenceMonosemous = 0.7
+	ConfidencePolysemous = 0.5
+	ConfidencePredicted  = 0.6
+)
+
+// confidence scores the sense idx chosen for c.
+func confidence(c *Candidate, idx int) float64 {
+	switch {
+	case c.Back != nil && containsAny(c.Back(c.Meanings[idx]), c.Lemmas):
+		return ConfidenceBackLink
+	case len(c.Meanings) == 1:
+		return ConfidenceMonosemous
+	default:
+		return ConfidencePolysemous
+	}
+}
+
+// predicted scores a predicted edge agreed by votes of pivots translations.
+func predicted(votes, pivots int) float64 {
+	if pivots == 0 {
+		return 0
+	}
+
+	return ConfidencePredicted * float64(votes) / float64(pivots)
+}
+
+// score sets the confidence of nodes: seed words are certain,
+// other nodes take the best confidence of their edges.
+func (t *Taxonomy) score() {
+	for _, n := range t.Nodes {
+		n.Confidence = 0
+		if n.Root || n.Curated {
+			n.Confidence = 1
+		}
+	}
+
+	for _, e := range t.Edges {
+		for _, id := range []string{e.From, e.To} {
+			if n := t.Node(id); n != nil && e.Confidence > n.Confidence {
+				n.Confidence = e.Confidence
+			}
+		}
+	}
+}
+
+// Filter drops the edges scoring below min and the nodes
+// no longer connected to a seed word or a curated node, along with their edges.
+func (t *Taxonomy) Filter(min float64) {
+	edges := t.Edges[:0]
+	for _, e := range t.Edges {
+		if e.Confidence >= min {
+			edges = append(edges, e)
+		} else {
+			t.trace(e.Tooltip, strings.Split(e.To, ":")[0], Dropped, "confidence %.2f below %.2f", e.Confidence, min)
+		}
+	}
+	t.Edges = edges
+
+	adj := make(map[string][]string)
+	for _, e := range t.Edges {
+		adj[e.From] = append(adj[e.From], e.To)
+		adj[e.To] = append(adj[e.To], e.From)
+	}
+
+	var queue []string
+	seen := make(map[string]bool)
+	for _, n := range t.Nodes {
+		if n.Root || n.Curated {
+			queue = append(queue, n.ID)
+			seen[n.ID] = true
+		}
+	}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, next := range adj[id] {
+			if !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	nodes := t.Nodes[:0]
+	for _, n := range t.Nodes {
+		if seen[n.ID] {
+			nodes = append(nodes, n)
+		}
+	}
+	edges = t.Edges[:0]
+	for _, e := range t.Edges {
+		if seen[e.From] && seen[e.To] {
+			edges = append(edges, e)
+		}
+	}
+	t.Nodes, t.Edges = nodes, edges
+	t.reindex()
+}
@@ -0,0 +1,105 @@
+package graph
+
+import "strings"
+
+// FindCycles marks the hyperonymy edges lying on cycles and returns
+// the strongly connected components they form.
+func (t *Taxonomy) FindCycles() [][]string {
+	adj := make(map[string][]string)
+	for _, e := range t.Edges {
+		e.Cycle = false
+		if e.Relation == Hyperonymy {
+			adj[e.From] = append(adj[e.From], e.To)
+		}
+	}
+
+	cycles := [][]string{}
+	component := make(map[string]int)
+	for _, scc := range components(t.Nodes, adj) {
+		if len(scc) < 2 {
+			continue
+		}
+		for _, id := range scc {
+			component[id] = len(cycles) + 1
+		}
+		cycles = append(cycles, scc)
+	}
+
+	for _, e := range t.Edges {
+		if e.Relation == Hyperonymy && component[e.From] != 0 && component[e.From] == component[e.To] {
+			e.Cycle = true
+		}
+	}
+
+	return cycles
+}
+
+// BreakCycles drops the least confident edge of every cycle until none is left,
+// the dropped edges are kept in Broken.
+func (t *Taxonomy) BreakCycles() {
+	for len(t.FindCycles()) > 0 {
+		var weakest *Edge
+		for _, e := range t.Edges {
+			if e.Cycle && (weakest == nil || e.Confidence <= weakest.Confidence) {
+				weakest = e
+			}
+		}
+
+		t.RemoveEdge(weakest.From, weakest.To, weakest.Relation)
+		t.Broken = append(t.Broken, weakest)
+		t.trace(weakest.Tooltip, strings.Split(weakest.To, ":")[0], Dropped, "least confident edge of a cycle, %.2f", weakest.Confidence)
+	}
+}
+
+// components finds strongly connected components with Tarjan's algorithm.
+func components(nodes []*Node, adj map[string][]string) [][]string {
+	var (
+		sccs    [][]string
+		stack   []string
+		counter int
+	)
+	index := make(map[string]int)
+	low := make(map[string]int)
+	onStack := make(map[string]bool)
+
+	var visit func(v string)
+	visit = func(v string) {
+		counter++
+		index[v], low[v] = counter, counter
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for _, w := range adj[v] {
+			if index[w] == 0 {
+				visit(w)
+				if low[w] < low[v] {
+					low[v] = low[w]
+				}
+			} else if onStack[w] && index[w] < low[v] {
+				low[v] = index[w]
+			}
+		}
+
+		if low[v] == index[v] {
+			var scc []string
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				scc = append(scc, w)
+				if w == v {
+					break
+				}
+			}
+			sccs = append(sccs, scc)
+		}
+	}
+
+	for _, n := range nodes {
+		if index[n.ID] == 0 {
+			visit(n.ID)
+		}
+	}
+
+	return sccs
+}
@@ -0,0 +1,109 @@
+package graph
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestFindCycles(t *testing.T) {
+	tests := []struct {
+		name   string
+		edges  []string
+		cycles [][]string
+		// onCycles lists the edges marked as Cycle.
+		onCycles []string
+	}{
+		{
+			name:     "tree",
+			edges:    []string{"кот->кошка", "кошка->животное", "пёс->животное"},
+			cycles:   [][]string{},
+			onCycles: []string{},
+		},
+		{
+			name:     "loop of two",
+			edges:    []string{"мир->вселенная", "вселенная->мир", "мир->реальность"},
+			cycles:   [][]string{{"вселенная", "мир"}},
+			onCycles: []string{"мир->вселенная", "вселенная->мир"},
+		},
+		{
+			name:     "loop of three with a tail",
+			edges:    []string{"кот->a", "a->b", "b->c", "c->a"},
+			cycles:   [][]string{{"a", "b", "c"}},
+			onCycles: []string{"a->b", "b->c", "c->a"},
+		},
+		{
+			name:     "two loops",
+			edges:    []string{"a->b", "b->a", "b->c", "c->d", "d->c"},
+			cycles:   [][]string{{"a", "b"}, {"c", "d"}},
+			onCycles: []string{"a->b", "b->a", "c->d", "d->c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := taxonomyOf(tt.edges...)
+			cycles := g.FindCycles()
+			for _, c := range cycles {
+				sort.Strings(c)
+			}
+			sort.Slice(cycles, func(i, j int) bool {
+				return cycles[i][0] < cycles[j][0]
+			})
+			if !reflect.DeepEqual(cycles, tt.cycles) {
+				t.Errorf("FindCycles() = %v, want %v", cycles, tt.cycles)
+			}
+
+			onCycles := []string{}
+			for _, e := range g.Edges {
+				if e.Cycle {
+					onCycles = append(onCycles, e.From+"->"+e.To)
+				}
+			}
+			if !reflect.DeepEqual(onCycles, tt.onCycles) {
+				t.Errorf("edges on cycles = %v, want %v", onCycles, tt.onCycles)
+			}
+		})
+	}
+}
+
+func TestFindCyclesIgnoresSideRelations(t *testing.T) {
+	g := taxonomyOf("a->b")
+	g.AddEdge(&Edge{From: "b", To: "a", Relation: Synonymy})
+
+	if cycles := g.FindCycles(); len(cycles) != 0 {
+		t.Errorf("FindCycles() = %v, want none", cycles)
+	}
+}
+
+func TestBreakCycles(t *testing.T) {
+	g := taxonomyOf("кот->a", "a->b", "b->c", "c->a", "c->d", "d->c")
+	for _, e := range g.Edges {
+		e.Confidence = 1
+	}
+	g.Edge("b", "c", Hyperonymy).Confidence = 0.5
+	g.Edge("d", "c", Hyperonymy).Confidence = 0.6
+
+	g.BreakCycles()
+
+	if cycles := g.FindCycles(); len(cycles) != 0 {
+		t.Errorf("cycles left: %v", cycles)
+	}
+	if got, want := chains(g.Broken), []string{"b->c", "d->c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Broken = %v, want %v", got, want)
+	}
+	if got, want := chains(g.Edges), []string{"кот->a", "a->b", "c->a", "c->d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Edges = %v, want %v", got, want)
+	}
+}
+
+func TestDOTCycleEdges(t *testing.T) {
+	g := taxonomyOf("a->b", "b->a", "b->c")
+	g.FindCycles()
+
+	for _, e := range g.Edges {
+		if got := edgeAttrs(e)["color"]; e.Cycle != (got == "red") {
+			t.Errorf("%s->%s: color %q, on a cycle %t", e.From, e.To, got, e.Cycle)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type cytoscape struct {
+	Data     map[string]interface{} `json:"data"`
+	Elements cytoscapeElements      `json:"elements"`
+}
+
+type cytoscapeElements struct {
+	Nodes []cytoscapeElement `json:"nodes"`
+	Edges []cytoscapeElement `json:"edges"`
+}
+
+type cytoscapeElement struct {
+	Data map[string]interface{} `json:"data"`
+}
+
+// Cytoscape renders the taxonomy as Cytoscape.js JSON,
+// which Cytoscape desktop imports as .cyjs.
+func (t *Taxonomy) Cytoscape() ([]byte, error) {
+	doc := cytoscape{
+		Data: map[string]interface{}{"name": t.Name},
+		Elements: cytoscapeElements{
+			Nodes: []cytoscapeElement{},
+			Edges: []cytoscapeElement{},
+		},
+	}
+
+	for _, n := range t.Nodes {
+		data := map[string]interface{}{"id": n.ID, "name": n.ID}
+		for _, a := range nodeAttributes {
+			data[a.Name] = a.Value(n)
+		}
+		doc.Elements.Nodes = append(doc.Elements.Nodes, cytoscapeElement{Data: data})
+	}
+
+	for i, e := range t.Edges {
+		data := map[string]interface{}{
+			"id":     fmt.Sprintf("e%d", i),
+			"source": e.From,
+			"target": e.To,
+		}
+		for _, a := range edgeAttributes {
+			data[a.Name] = a.Value(e)
+		}
+		doc.Elements.Edges = append(doc.Elements.Edges, cytoscapeElement{Data: data})
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
@@ -0,0 +1,196 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+
+	dot "github.com/awalterschulze/gographviz"
+)
+
+// Colours of the diff overlay, unchanged nodes and edges are greyed out.
+const (
+	addedColor     = "darkgreen"
+	removedColor   = "red"
+	changedColor   = "orange"
+	unchangedColor = "gray60"
+)
+
+// SenseChange is a word whose senses differ between the compared taxonomies,
+// Old and New list the IDs of its nodes, e.g. мир:0 and мир:3.
+type SenseChange struct {
+	Title string   `json:"title"`
+	Old   []string `json:"old"`
+	New   []string `json:"new"`
+}
+
+// MeaningChange is a node whose meaning text differs,
+// which happens after Wiktionary edits or parser changes.
+type MeaningChange struct {
+	ID  string `json:"id"`
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// EdgeChange is an edge kept between the same nodes along the same relation
+// but derived differently, e.g. own in Old and predicted in New.
+type EdgeChange struct {
+	Old *Edge `json:"old"`
+	New *Edge `json:"new"`
+}
+
+// Diff lists the changes turning the taxonomy A into B.
+type Diff struct {
+	A            string          `json:"a"`
+	B            string          `json:"b"`
+	AddedNodes   []*Node         `json:"added_nodes"`
+	RemovedNodes []*Node         `json:"removed_nodes"`
+	AddedEdges   []*Edge         `json:"added_edges"`
+	RemovedEdges []*Edge         `json:"removed_edges"`
+	ChangedEdges []EdgeChange    `json:"changed_edges"`
+	Senses       []SenseChange   `json:"senses"`
+	Meanings     []MeaningChange `json:"meanings"`
+
+	a, b *Taxonomy
+}
+
+// Compare diffs the taxonomies a and b, nodes are matched by ID and edges
+// by their ends and relation. Edges of another kind make up ChangedEdges,
+// words kept with other senses Senses.
+func Compare(a, b *Taxonomy) *Diff {
+	d := &Diff{
+		A:            a.Name,
+		B:            b.Name,
+		AddedNodes:   []*Node{},
+		RemovedNodes: []*Node{},
+		AddedEdges:   []*Edge{},
+		RemovedEdges: []*Edge{},
+		ChangedEdges: []EdgeChange{},
+		Senses:       []SenseChange{},
+		Meanings:     []MeaningChange{},
+		a:            a,
+		b:            b,
+	}
+
+	for _, n := range a.Nodes {
+		if m := b.Node(n.ID); m == nil {
+			d.RemovedNodes = append(d.RemovedNodes, n)
+		} else if m.Meaning != n.Meaning {
+			d.Meanings = append(d.Meanings, MeaningChange{ID: n.ID, Old: n.Meaning, New: m.Meaning})
+		}
+	}
+	for _, n := range b.Nodes {
+		if a.Node(n.ID) == nil {
+			d.AddedNodes = append(d.AddedNodes, n)
+		}
+	}
+
+	for _, e := range a.Edges {
+		if f := b.Edge(e.From, e.To, e.Relation); f == nil {
+			d.RemovedEdges = append(d.RemovedEdges, e)
+		} else if f.Kind != e.Kind {
+			d.ChangedEdges = append(d.ChangedEdges, EdgeChange{Old: e, New: f})
+		}
+	}
+	for _, e := range b.Edges {
+		if a.Edge(e.From, e.To, e.Relation) == nil {
+			d.AddedEdges = append(d.AddedEdges, e)
+		}
+	}
+
+	before, after := senses(d.RemovedNodes), senses(d.AddedNodes)
+	var titles []string
+	for title := range before {
+		if _, ok := after[title]; ok {
+			titles = append(titles, title)
+		}
+	}
+	sort.Strings(titles)
+	for _, title := range titles {
+		d.Senses = append(d.Senses, SenseChange{Title: title, Old: before[title], New: after[title]})
+	}
+
+	return d
+}
+
+// DOT renders both taxonomies overlaid: added nodes and edges are green,
+// removed ones red and dashed, changed edges and words with changed senses
+// or meanings orange.
+func (d *Diff) DOT() string {
+	g := dot.NewGraph()
+	g.Directed = true
+	g.Name = glue(fmt.Sprintf("%s → %s", d.A, d.B))
+	_ = g.AddAttr(g.Name, "label", g.Name)
+
+	changed := make(map[string]bool)
+	for _, s := range d.Senses {
+		for _, id := range append(s.Old, s.New...) {
+			changed[id] = true
+		}
+	}
+	for _, m := range d.Meanings {
+		changed[m.ID] = true
+	}
+
+	addNode := func(n *Node, color string, attrs map[string]string) {
+		if changed[n.ID] {
+			color = changedColor
+		}
+		attrs["color"] = color
+		attrs["fontcolor"] = color
+		if attrs["tooltip"] == "" {
+			attrs["tooltip"] = glue(n.Meaning)
+		}
+		_ = g.AddNode(g.Name, glue(n.ID), attrs)
+	}
+
+	for _, n := range d.b.Nodes {
+		color, attrs := unchangedColor, map[string]string{}
+		if d.a.Node(n.ID) == nil {
+			color = addedColor
+		} else if old := d.a.Node(n.ID).Meaning; old != n.Meaning {
+			attrs["tooltip"] = glue(fmt.Sprintf("%s → %s", old, n.Meaning))
+		}
+		addNode(n, color, attrs)
+	}
+	for _, n := range d.RemovedNodes {
+		addNode(n, removedColor, map[string]string{"style": "dashed"})
+	}
+
+	addEdge := func(e *Edge, color, tooltip string) {
+		attrs := map[string]string{"tooltip": glue(tooltip)}
+		for k, v := range relationStyles[e.Relation] {
+			attrs[k] = v
+		}
+		attrs["color"] = color
+		if color == removedColor {
+			attrs["style"] = "dashed"
+		}
+		_ = g.AddEdge(glue(e.From), glue(e.To), true, attrs)
+	}
+
+	for _, e := range d.b.Edges {
+		color, tooltip := unchangedColor, e.Tooltip
+		if old := d.a.Edge(e.From, e.To, e.Relation); old == nil {
+			color = addedColor
+		} else if old.Kind != e.Kind {
+			color = changedColor
+			tooltip = fmt.Sprintf("%s (%s) → %s (%s)", old.Tooltip, old.Kind, e.Tooltip, e.Kind)
+		}
+		addEdge(e, color, tooltip)
+	}
+	for _, e := range d.RemovedEdges {
+		addEdge(e, removedColor, e.Tooltip)
+	}
+
+	return g.String()
+}
+
+// senses groups the IDs of nodes by title.
+func senses(nodes []*Node) map[string][]string {
+	ids := make(map[string][]string)
+	for _, n := range nodes {
+		ids[n.Title] = append(ids[n.Title], n.ID)
+	}
+
+	return ids
+}
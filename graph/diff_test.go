@@ -0,0 +1,66 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func ids(nodes []*Node) []string {
+	list := []string{}
+	for _, n := range nodes {
+		list = append(list, n.ID)
+	}
+
+	return list
+}
+
+func TestCompareTaxonomies(t *testing.T) {
+	a := taxonomyOf("кот->кошка", "кошка->животное", "мир:0->вселенная", "пёс->животное")
+	b := taxonomyOf("кот->кошка", "кошка->зверь", "мир:3->община", "пёс->животное")
+	b.Edge("кот", "кошка", Hyperonymy).Kind = Predicted
+	a.AddEdge(&Edge{From: "пёс", To: "кот", Relation: Synonymy})
+	b.AddEdge(&Edge{From: "пёс", To: "кот", Relation: Antonymy})
+	a.Node("кот").Meaning = "домашнее животное"
+	b.Node("кот").Meaning = "домашнее животное семейства кошачьих"
+
+	d := Compare(a, b)
+
+	if got, want := ids(d.AddedNodes), []string{"зверь", "мир:3", "община"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AddedNodes = %v, want %v", got, want)
+	}
+	if got, want := ids(d.RemovedNodes), []string{"мир:0", "вселенная"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("RemovedNodes = %v, want %v", got, want)
+	}
+	if got, want := chains(d.AddedEdges), []string{"кошка->зверь", "мир:3->община", "пёс->кот"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AddedEdges = %v, want %v", got, want)
+	}
+	if got, want := chains(d.RemovedEdges), []string{"кошка->животное", "мир:0->вселенная", "пёс->кот"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("RemovedEdges = %v, want %v", got, want)
+	}
+	if d.AddedEdges[2].Relation != Antonymy || d.RemovedEdges[2].Relation != Synonymy {
+		t.Errorf("edges between пёс and кот differ by relation: %+v, %+v", d.AddedEdges[2], d.RemovedEdges[2])
+	}
+
+	if len(d.ChangedEdges) != 1 {
+		t.Fatalf("ChangedEdges = %+v, want кот->кошка", d.ChangedEdges)
+	}
+	if c := d.ChangedEdges[0]; c.Old.Kind != "" || c.New.Kind != Predicted || c.New.From != "кот" || c.New.To != "кошка" {
+		t.Errorf("ChangedEdges[0] = %+v -> %+v", c.Old, c.New)
+	}
+
+	if want := []SenseChange{{Title: "мир", Old: []string{"мир:0"}, New: []string{"мир:3"}}}; !reflect.DeepEqual(d.Senses, want) {
+		t.Errorf("Senses = %+v, want %+v", d.Senses, want)
+	}
+	want := []MeaningChange{{ID: "кот", Old: "домашнее животное", New: "домашнее животное семейства кошачьих"}}
+	if !reflect.DeepEqual(d.Meanings, want) {
+		t.Errorf("Meanings = %+v, want %+v", d.Meanings, want)
+	}
+}
+
+func TestCompareEqualTaxonomies(t *testing.T) {
+	d := Compare(taxonomyOf("кот->кошка"), taxonomyOf("кот->кошка"))
+
+	if len(d.AddedNodes)+len(d.RemovedNodes)+len(d.AddedEdges)+len(d.RemovedEdges)+len(d.ChangedEdges)+len(d.Senses)+len(d.Meanings) != 0 {
+		t.Errorf("Compare of equal taxonomies = %+v", d)
+	}
+}
@@ -0,0 +1,112 @@
+package graph
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	dot "github.com/awalterschulze/gographviz"
+)
+
+var relationStyles = map[Relation]map[string]string{
+	Synonymy: {"style": "dotted", "dir": "none"},
+	Antonymy: {"style": "dashed", "arrowhead": "tee", "dir": "both", "arrowtail": "tee"},
+	Meronymy: {"arrowhead": "diamond"},
+	Holonymy: {"arrowhead": "odiamond"},
+	// Translation links the languages of an aligned taxonomy
+	// without affecting the layout of each.
+	Translation: {"style": "dashed", "dir": "none", "constraint": "false"},
+}
+
+// DOT renders the taxonomy in the graphviz language,
+// every language of an aligned taxonomy gets its own cluster.
+// The edges dropped by Reduce are only drawn with showRedundant,
+// ids starting with redundant let the view page toggle them.
+func (t *Taxonomy) DOT(showRedundant bool) string {
+	g := dot.NewGraph()
+	g.Directed = true
+	g.Name = glue(t.Name)
+
+	clusters := make(map[string]string)
+	for i, lang := range t.Langs {
+		clusters[lang] = fmt.Sprintf("cluster%d", i)
+		_ = g.AddSubGraph(g.Name, clusters[lang], map[string]string{"label": glue(lang)})
+	}
+
+	for _, n := range t.Nodes {
+		attrs := map[string]string{
+			"tooltip":  glue(n.Meaning),
+			"penwidth": penwidth(n.Confidence),
+		}
+		if n.Root && n.Polysemous {
+			attrs["color"] = "green"
+		}
+		if n.Curated {
+			attrs["color"] = "purple"
+		}
+		parent, label := g.Name, n.ID
+		if cluster, ok := clusters[n.Lang]; ok {
+			parent, label = cluster, strings.TrimSuffix(n.ID, "@"+n.Lang)
+		}
+		if len(n.Lemmas) > 1 {
+			label += `\n` + strings.Join(n.Lemmas[1:], ", ")
+		}
+		if label != n.ID {
+			attrs["label"] = glue(label)
+		}
+		if n.Truncated {
+			attrs["style"] = "dashed"
+		}
+		_ = g.AddNode(parent, glue(n.ID), attrs)
+	}
+
+	for _, e := range t.Edges {
+		_ = g.AddEdge(glue(e.From), glue(e.To), true, edgeAttrs(e))
+	}
+
+	if !showRedundant {
+		return g.String()
+	}
+	for i, e := range t.Redundant {
+		attrs := edgeAttrs(e)
+		attrs["id"] = glue(fmt.Sprintf("redundant%d", i))
+		attrs["constraint"] = "false"
+		_ = g.AddEdge(glue(e.From), glue(e.To), true, attrs)
+	}
+
+	return g.String()
+}
+
+func edgeAttrs(e *Edge) map[string]string {
+	attrs := map[string]string{
+		"penwidth": penwidth(e.Confidence),
+		"tooltip":  glue(e.Tooltip),
+	}
+	for k, v := range relationStyles[e.Relation] {
+		attrs[k] = v
+	}
+	if e.Kind == Curated {
+		attrs["color"] = "purple"
+	} else if !e.Strict && e.Polysemous {
+		switch e.Kind {
+		case Own:
+			attrs["color"] = "green"
+		case Predicted:
+			attrs["color"] = "blue"
+		}
+	}
+	if e.Cycle {
+		attrs["color"] = "red"
+	}
+
+	return attrs
+}
+
+// penwidth maps confidence from 0 to 1 onto lines from 1 to 5 points wide.
+func penwidth(confidence float64) string {
+	return strconv.FormatFloat(1+4*confidence, 'f', 1, 64)
+}
+
+func glue(s string) string {
+	return fmt.Sprintf("\"%s\"", s)
+}
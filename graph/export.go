@@ -0,0 +1,38 @@
+package graph
+
+import "strings"
+
+// nodeAttribute is a column of the node table of GraphML, GEXF and
+// Cytoscape exports, Type is its GraphML type.
+type nodeAttribute struct {
+	Name  string
+	Type  string
+	Value func(*Node) interface{}
+}
+
+// edgeAttribute is a column of the edge table of exports, see nodeAttribute.
+type edgeAttribute struct {
+	Name  string
+	Type  string
+	Value func(*Edge) interface{}
+}
+
+var nodeAttributes = []nodeAttribute{
+	{"title", "string", func(n *Node) interface{} { return n.Title }},
+	{"lang", "string", func(n *Node) interface{} { return n.Lang }},
+	{"sense", "int", func(n *Node) interface{} { return n.Sense }},
+	{"meaning", "string", func(n *Node) interface{} { return n.Meaning }},
+	{"lemmas", "string", func(n *Node) interface{} { return strings.Join(n.Lemmas, ", ") }},
+	{"root", "boolean", func(n *Node) interface{} { return n.Root }},
+	{"polysemous", "boolean", func(n *Node) interface{} { return n.Polysemous }},
+	{"confidence", "double", func(n *Node) interface{} { return n.Confidence }},
+}
+
+var edgeAttributes = []edgeAttribute{
+	{"relation", "string", func(e *Edge) interface{} { return string(e.Relation) }},
+	{"kind", "string", func(e *Edge) interface{} { return string(e.Kind) }},
+	{"tooltip", "string", func(e *Edge) interface{} { return e.Tooltip }},
+	{"strict", "boolean", func(e *Edge) interface{} { return e.Strict }},
+	{"cycle", "boolean", func(e *Edge) interface{} { return e.Cycle }},
+	{"confidence", "double", func(e *Edge) interface{} { return e.Confidence }},
+}
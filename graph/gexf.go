@@ -0,0 +1,118 @@
+package graph
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+const gexfNamespace = "http://www.gexf.net/1.2draft"
+
+// gexfTypes maps GraphML attribute types onto GEXF ones.
+var gexfTypes = map[string]string{
+	"string":  "string",
+	"int":     "integer",
+	"double":  "double",
+	"boolean": "boolean",
+}
+
+type gexf struct {
+	XMLName xml.Name  `xml:"gexf"`
+	XMLNS   string    `xml:"xmlns,attr"`
+	Version string    `xml:"version,attr"`
+	Meta    gexfMeta  `xml:"meta"`
+	Graph   gexfGraph `xml:"graph"`
+}
+
+type gexfMeta struct {
+	Creator     string `xml:"creator"`
+	Description string `xml:"description"`
+}
+
+type gexfGraph struct {
+	Mode            string           `xml:"mode,attr"`
+	DefaultEdgeType string           `xml:"defaultedgetype,attr"`
+	Attributes      []gexfAttributes `xml:"attributes"`
+	Nodes           []gexfNode       `xml:"nodes>node"`
+	Edges           []gexfEdge       `xml:"edges>edge"`
+}
+
+type gexfAttributes struct {
+	Class      string          `xml:"class,attr"`
+	Attributes []gexfAttribute `xml:"attribute"`
+}
+
+type gexfAttribute struct {
+	ID    string `xml:"id,attr"`
+	Title string `xml:"title,attr"`
+	Type  string `xml:"type,attr"`
+}
+
+type gexfNode struct {
+	ID     string      `xml:"id,attr"`
+	Label  string      `xml:"label,attr"`
+	Values []gexfValue `xml:"attvalues>attvalue"`
+}
+
+type gexfEdge struct {
+	ID     string      `xml:"id,attr"`
+	Source string      `xml:"source,attr"`
+	Target string      `xml:"target,attr"`
+	Label  string      `xml:"label,attr"`
+	Weight float64     `xml:"weight,attr"`
+	Values []gexfValue `xml:"attvalues>attvalue"`
+}
+
+type gexfValue struct {
+	For   string `xml:"for,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// GEXF renders the taxonomy as a GEXF 1.2 document for Gephi,
+// edges weigh their confidence.
+func (t *Taxonomy) GEXF() ([]byte, error) {
+	doc := gexf{
+		XMLNS:   gexfNamespace,
+		Version: "1.2",
+		Meta:    gexfMeta{Creator: "wikitologies", Description: t.Name},
+		Graph:   gexfGraph{Mode: "static", DefaultEdgeType: "directed"},
+	}
+
+	nodes := gexfAttributes{Class: "node"}
+	for _, a := range nodeAttributes {
+		nodes.Attributes = append(nodes.Attributes, gexfAttribute{ID: a.Name, Title: a.Name, Type: gexfTypes[a.Type]})
+	}
+	edges := gexfAttributes{Class: "edge"}
+	for _, a := range edgeAttributes {
+		edges.Attributes = append(edges.Attributes, gexfAttribute{ID: a.Name, Title: a.Name, Type: gexfTypes[a.Type]})
+	}
+	doc.Graph.Attributes = []gexfAttributes{nodes, edges}
+
+	for _, n := range t.Nodes {
+		node := gexfNode{ID: n.ID, Label: n.ID}
+		for _, a := range nodeAttributes {
+			node.Values = append(node.Values, gexfValue{For: a.Name, Value: fmt.Sprint(a.Value(n))})
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, node)
+	}
+
+	for i, e := range t.Edges {
+		edge := gexfEdge{
+			ID:     fmt.Sprintf("e%d", i),
+			Source: e.From,
+			Target: e.To,
+			Label:  string(e.Relation),
+			Weight: e.Confidence,
+		}
+		for _, a := range edgeAttributes {
+			edge.Values = append(edge.Values, gexfValue{For: a.Name, Value: fmt.Sprint(a.Value(e))})
+		}
+		doc.Graph.Edges = append(doc.Graph.Edges, edge)
+	}
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), data...), nil
+}
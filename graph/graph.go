@@ -1,383 +1,808 @@
 package graph
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"strconv"
 	"strings"
-	"time"
 
-	dot "github.com/awalterschulze/gographviz"
 	"github.com/gomodule/redigo/redis"
 
 	"github.com/stillpiercer/wikitologies/parser"
 	wikt "github.com/stillpiercer/wikitologies/wiktionary"
 )
 
-type kind string
+// predictedParams describe a hyperonym predicted through the hyperonym h
+// of a translation into the pivot language.
+type predictedParams struct {
+	pivot      string
+	h          string
+	tooltip    string
+	polysemous bool
+	confidence float64
+	pivots     []string
+}
+
+type Direction string
 
 const (
-	own       kind = "own"
-	predicted kind = "predicted"
+	Up   Direction = "up"
+	Down Direction = "down"
+	Both Direction = "both"
 )
 
-type predictedParams struct {
-	ru         string
-	tooltip    string
-	polysemous bool
+// Options tune Build. Zero limits mean no limit.
+type Options struct {
+	Strict  bool
+	Presets map[string]int
+	// Direction of expansion from the seed words, Up by default.
+	// Hyponyms are followed through own relations only.
+	Direction Direction
+	// Synsets merges mutually synonymous senses into a single node.
+	Synsets bool
+	// Relations enables side relations, mapping each of SideRelations
+	// to the number of hops followed along it.
+	Relations map[Relation]int
+	// Selectors is the chain picking the senses of words,
+	// the default one depends on Strict.
+	Selectors []SenseSelector
+	// BreakCycles drops the weakest edge of every hyperonym cycle.
+	BreakCycles bool
+	// Reduce removes hyperonymy edges implied by longer paths.
+	Reduce bool
+	// MinConfidence drops edges scoring below it, see Filter.
+	MinConfidence float64
+	// Pivots are the languages hyperonyms of foreign words are predicted
+	// through, Russian by default.
+	Pivots []string
+	// Patch is the manual curation applied on top of the built graph,
+	// before confidence filtering, cycle breaking and reduction.
+	Patch Patch
+	// Progress receives copies of the events of Trace as they happen,
+	// along with Fetching and Fetched ones.
+	Progress func(*Event)
+
+	// MaxDepth bounds the distance from the seed words.
+	MaxDepth int
+	// MaxNodes bounds the number of nodes.
+	MaxNodes int
+	// MaxFetches bounds the number of GetWord calls.
+	MaxFetches int
 }
 
-var global = map[string]int{
-	"реальность": 1,
-	"организм":   1,
-	"мир":        3,
+var errBudget = errors.New("fetch budget exhausted")
+
+type builder struct {
+	lang    string
+	opts    Options
+	pool    *redis.Pool
+	g       *Taxonomy
+	stack   stack
+	fetches int
+	// aliases maps synonyms merged into a synset to the synset node.
+	aliases map[string]string
+	// side holds nodes reached through side relations only.
+	side map[string]bool
+	// senses maps nodes to their meanings.
+	senses map[string]*parser.Meaning
 }
 
-func Build(titles []string, lang string, strict bool, presets map[string]int, pool *redis.Pool) (*dot.Graph, error) {
+func Build(titles []string, lang string, opts Options, pool *redis.Pool) (*Taxonomy, error) {
+	b, err := build(titles, lang, opts, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.g, nil
+}
+
+// build runs Build keeping the builder, whose senses the callers may need.
+func build(titles []string, lang string, opts Options, pool *redis.Pool) (*builder, error) {
 	log.Printf("=== building %s ===", titles)
-	g := dot.NewGraph()
-	g.Directed = true
-	g.Name = glue(fmt.Sprintf("%s (%s)", titles, lang))
+	b := &builder{
+		lang: lang,
+		opts: opts,
+		pool: pool,
+		g:    NewTaxonomy(fmt.Sprintf("%s (%s)", titles, lang), lang),
+
+		aliases: make(map[string]string),
+		side:    make(map[string]bool),
+		senses:  make(map[string]*parser.Meaning),
+	}
+
+	b.g.progress = opts.Progress
+	// Limits keep the nearest neighbourhood of the seeds: walking breadth
+	// first gives nodes their shortest distance before MaxDepth applies.
+	b.stack.fifo = opts.MaxDepth > 0 || opts.MaxNodes > 0 || opts.MaxFetches > 0
 
-	stack := stack{}
 	for _, title := range titles {
-		word, err := GetWord(title, pool)
-		if err != nil {
-			if err == wikt.ErrMissing {
-				log.Println(title, err)
-				continue
-			}
+		if err := b.seed(title); err != nil {
 			return nil, err
 		}
+	}
 
-		var meanings parser.Meanings
-		if meanings = word.ByLanguage(lang); meanings == nil {
-			log.Printf("[WARNING] %s язык для слова %s не найден", lang, title)
-			continue
+	for !b.stack.empty() {
+		p := b.stack.pop()
+		if id, ok := b.aliases[p.t]; ok {
+			p.t = id
 		}
+		step := b.step
+		if p.rel != "" {
+			step = b.stepRelation
+		} else if p.down {
+			step = b.stepDown
+		}
+		if err := step(p); err != nil {
+			return nil, err
+		}
+	}
 
-		idx := index(title, lang, meanings, presets)
-		l := len(meanings)
-		if idx >= l {
-			return nil, fmt.Errorf("[ERROR] некорректные параметры запроса для слова %s: запрошено значение %d (всего доступно %d)", title, idx, l)
+	if len(opts.Patch) > 0 {
+		b.g.Apply(opts.Patch)
+	}
+	if opts.MinConfidence > 0 {
+		b.g.Filter(opts.MinConfidence)
+	}
+	b.g.score()
+
+	b.g.Cycles = b.g.FindCycles()
+	if len(b.g.Cycles) > 0 {
+		log.Printf("%d hyperonym cycles found: %s", len(b.g.Cycles), b.g.Cycles)
+		if opts.BreakCycles {
+			b.g.BreakCycles()
 		}
+	}
+	if opts.Reduce {
+		b.g.Reduce()
+	}
 
-		attrs := map[string]string{
-			"tooltip":  glue(meanings[idx].Value),
-			"penwidth": "3",
+	log.Printf("=== done %s ===", titles)
+	return b, nil
+}
+
+func (b *builder) seed(title string) error {
+	word, err := b.fetch(title)
+	if err != nil {
+		if err == wikt.ErrMissing {
+			b.g.trace(title, title, Missing, "%v", err)
+			return nil
 		}
-		name := title
-		if l > 1 {
-			attrs["color"] = "green"
-			name += fmt.Sprintf(":%d", idx)
+		if err == errBudget {
+			b.g.trace(title, title, Limited, "%v", err)
+			b.truncate(title)
+			return nil
 		}
-		_ = g.AddNode(g.Name, glue(name), attrs)
+		return err
+	}
+
+	var meanings parser.Meanings
+	if meanings = word.ByLanguage(b.lang); meanings == nil {
+		b.g.trace(title, title, Missing, "[WARNING] %s язык для слова %s не найден", b.lang, title)
+		return nil
+	}
 
-		if len(meanings[idx].Hyperonyms) > 0 {
-			stack.push(name, meanings[idx].Hyperonyms)
-			log.Printf("%s own: %s", name, meanings[idx].Hyperonyms)
+	idx, err := b.choose(&Candidate{Key: title, Title: title, Lang: b.lang, Meanings: meanings})
+	if err != nil {
+		return err
+	}
+	l := len(meanings)
+
+	name := title
+	if l > 1 {
+		name += fmt.Sprintf(":%d", idx)
+	}
+	if id, ok := b.aliases[name]; ok {
+		b.g.Node(id).Root = true
+		return nil
+	}
+	n := &Node{
+		ID:         name,
+		Title:      title,
+		Lang:       b.lang,
+		Sense:      idx,
+		Meaning:    meanings[idx].Value,
+		Polysemous: l > 1,
+		Root:       true,
+		Synonyms:   meanings[idx].Synonyms,
+	}
+	b.g.AddNode(n)
+	b.senses[n.ID] = meanings[idx]
+	b.g.record(&Event{Key: title, Title: title, Action: Added, Reason: "seed node " + n.ID, Node: n})
+
+	return b.grow(n, meanings[idx], b.opts.Direction)
+}
+
+func (b *builder) step(p pair) error {
+	t, h, pp := p.t, p.h, p.pp
+	var kind Kind
+	if pp == nil {
+		kind = Own
+	} else {
+		kind = Predicted
+	}
+	log.Printf("%s -> %s [%s]: checking...", t, h, kind)
+
+	word, err := b.fetch(h)
+	if err != nil {
+		if err == wikt.ErrMissing {
+			b.g.trace(t+"->"+h, h, Missing, "%v", err)
+			return nil
 		}
-		if lang != wikt.Russian {
-			hs, rus, err := predict(title, lang, meanings[idx], strict, presets, meanings[idx].Hyperonyms, pool)
-			if err != nil {
-				return nil, err
-			}
-			if len(hs) > 0 {
-				stack.push2(name, hs, rus)
-				log.Printf("%s predicted: %s %s", name, hs, rus)
-			}
+		if err == errBudget {
+			b.g.trace(t+"->"+h, h, Limited, "%v", err)
+			b.truncate(t)
+			return nil
 		}
+		return err
 	}
 
-	for !stack.empty() {
-		t, h, pp := stack.pop()
-		var kind kind
-		if pp == nil {
-			kind = own
-		} else {
-			kind = predicted
-		}
-		log.Printf("%s -> %s [%s]: checking...", t, h, kind)
+	meanings := word.ByLanguage(b.lang)
+	l := len(meanings)
+	if l == 0 {
+		return nil
+	}
 
-		word, err := GetWord(h, pool)
+	idx := -1
+	var polysemous bool
+	var score float64
+	var pivots []string
+	tooltip := fmt.Sprintf("%s->%s", t, h)
+	switch kind {
+	case Own:
+		c := b.candidate(t, h, tooltip, meanings, hyponyms)
+		idx, err = b.choose(c)
 		if err != nil {
-			if err == wikt.ErrMissing {
-				log.Println(h, err)
-				continue
-			}
-			return nil, err
+			return err
 		}
-
-		meanings := word.ByLanguage(lang)
-		l := len(meanings)
-		if l == 0 {
-			continue
+		if idx != -1 {
+			score = confidence(c, idx)
 		}
-
-		idx := -1
-		var polysemous bool
-		tooltip := fmt.Sprintf("%s->%s", t, h)
-		switch kind {
-		case own:
-			if strict {
-				for i, m := range meanings {
-					if contains(m.Hyponyms, strings.Split(t, ":")[0]) {
-						idx = i
-						break
-					}
-				}
-			} else {
-				idx = index(tooltip, lang, meanings, presets)
-				if idx >= l {
-					return nil, fmt.Errorf("[ERROR] некорректные параметры запроса для слова %s: запрошено значение %d (всего доступно %d)", h, idx, l)
-				}
-			}
-			polysemous = l > 1
-			if polysemous {
-				tooltip += ":" + strconv.Itoa(idx)
-			}
-		case predicted:
-			for i, m := range meanings {
-				if contains(m.Translations.ByLanguage(wikt.Russian), pp.ru) {
-					idx = i
-					break
-				}
+		polysemous = l > 1
+		if polysemous {
+			tooltip += ":" + strconv.Itoa(idx)
+		}
+	case Predicted:
+		polysemous = pp.polysemous
+		tooltip = pp.tooltip
+		score = pp.confidence
+		pivots = pp.pivots
+		for i, m := range meanings {
+			if contains(m.Translations.ByLanguage(pp.pivot), pp.h) {
+				idx = i
+				break
 			}
-			polysemous = pp.polysemous
-			tooltip = pp.tooltip
 		}
 		if idx == -1 {
-			log.Printf("%s -> %s [%s]: denied", t, h, kind)
-			continue
+			b.g.trace(tooltip, h, Denied, "no sense of %s translates to %s (%s)", h, pp.h, pp.pivot)
+			return nil
 		}
+		b.g.trace(tooltip, h, Selected, "%d/%d: translates to %s (%s), %d%% of pivots agree",
+			idx, l, pp.h, pp.pivot, int(100*score/ConfidencePredicted))
+	}
+	if idx == -1 {
+		return nil
+	}
 
-		name := h
-		if l > 1 {
-			name += fmt.Sprintf(":%d", idx)
-			log.Printf("%s -> %s [%s]: %d/%d selected", t, h, kind, idx, l)
-		}
+	name := h
+	if l > 1 {
+		name += fmt.Sprintf(":%d", idx)
+	}
 
-		if _, ok := g.Nodes.Lookup[glue(name)]; ok {
-			log.Printf("%s node exists", name)
-			if _, ok := g.Edges.SrcToDsts[glue(t)][glue(name)]; !ok && t != name {
-				addEdge(g, t, name, tooltip, kind, strict, polysemous)
-				log.Printf("%s -> %s [%s]: edge added", t, name, kind)
-			}
-			continue
-		}
+	edge := &Edge{
+		From:     t,
+		To:       name,
+		Relation: Hyperonymy,
+		Kind:     kind,
+		Provenance: Provenance{
+			Tooltip:    tooltip,
+			Strict:     b.opts.Strict,
+			Polysemous: polysemous,
+			Confidence: score,
+			Pivots:     pivots,
+		},
+	}
 
-		_ = g.AddNode(g.Name, glue(name), map[string]string{
-			"tooltip":  glue(meanings[idx].Value),
-			"penwidth": "3",
-		})
-		log.Printf("%s node added", name)
-		addEdge(g, t, name, tooltip, kind, strict, polysemous)
-		log.Printf("%s -> %s [%s]: edge added", t, name, kind)
+	return b.attach(p, &Node{
+		ID:         name,
+		Title:      h,
+		Lang:       b.lang,
+		Sense:      idx,
+		Meaning:    meanings[idx].Value,
+		Polysemous: l > 1,
+	}, edge, meanings[idx])
+}
+
+// stepDown checks the hyponym p.h of the node p.t: in strict mode
+// the sense of the hyponym has to list p.t among its hyperonyms.
+func (b *builder) stepDown(p pair) error {
+	t, h := p.t, p.h
+	log.Printf("%s <- %s [%s]: checking...", t, h, Own)
 
-		if len(meanings[idx].Hyperonyms) > 0 {
-			stack.push(name, meanings[idx].Hyperonyms)
-			log.Printf("%s own: %s", name, meanings[idx].Hyperonyms)
+	word, err := b.fetch(h)
+	if err != nil {
+		if err == wikt.ErrMissing {
+			b.g.trace(t+"<-"+h, h, Missing, "%v", err)
+			return nil
 		}
-		if lang != wikt.Russian {
-			hs, pp, err := predict(h, lang, meanings[idx], strict, presets, meanings[idx].Hyperonyms, pool)
-			if err != nil {
-				return nil, err
-			}
-			if len(hs) > 0 {
-				stack.push2(name, hs, pp)
-				log.Printf("%s predicted: %s", name, hs)
-			}
+		if err == errBudget {
+			b.g.trace(t+"<-"+h, h, Limited, "%v", err)
+			b.truncate(t)
+			return nil
 		}
+		return err
 	}
 
-	log.Printf("=== done %s ===", titles)
-	return g, nil
+	meanings := word.ByLanguage(b.lang)
+	l := len(meanings)
+	if l == 0 {
+		return nil
+	}
+
+	tooltip := fmt.Sprintf("%s<-%s", t, h)
+	c := b.candidate(t, h, tooltip, meanings, hyperonyms)
+	idx, err := b.choose(c)
+	if err != nil {
+		return err
+	}
+	if idx == -1 {
+		return nil
+	}
+
+	name := h
+	if l > 1 {
+		name += fmt.Sprintf(":%d", idx)
+		tooltip += ":" + strconv.Itoa(idx)
+	}
+
+	return b.attach(p, &Node{
+		ID:         name,
+		Title:      h,
+		Lang:       b.lang,
+		Sense:      idx,
+		Meaning:    meanings[idx].Value,
+		Polysemous: l > 1,
+	}, &Edge{
+		From:     name,
+		To:       t,
+		Relation: Hyperonymy,
+		Kind:     Own,
+		Provenance: Provenance{
+			Tooltip:    tooltip,
+			Strict:     b.opts.Strict,
+			Polysemous: l > 1,
+			Confidence: confidence(c, idx),
+		},
+	}, meanings[idx])
 }
 
-func index(title, lang string, meanings parser.Meanings, presets map[string]int) int {
-	if i, ok := presets[title]; ok {
-		return i
+// attach adds the node n reached from p.t through e and expands it further
+// in the direction of p. Known nodes only get the edge.
+func (b *builder) attach(p pair, n *Node, e *Edge, meaning *parser.Meaning) error {
+	if id, ok := b.aliases[n.ID]; ok {
+		if e.From == n.ID {
+			e.From = id
+		} else {
+			e.To = id
+		}
+		n.ID = id
+	}
+
+	dir := Up
+	if p.down {
+		dir = Down
 	}
 
-	if strings.Contains(title, "->") {
-		title = strings.Split(title, "->")[1]
+	if existing := b.g.Node(n.ID); existing != nil {
+		log.Printf("%s node exists", n.ID)
+		if b.g.Edge(e.From, e.To, e.Relation) == nil && e.From != e.To {
+			b.g.AddEdge(e)
+			b.edgeAdded(e, n.Title)
+		}
+		if p.rel != "" {
+			return nil
+		}
+		if b.side[n.ID] {
+			delete(b.side, n.ID)
+			existing.Depth = p.depth + 1
+			return b.grow(existing, meaning, dir)
+		}
+		if depth := p.depth + 1; depth < existing.Depth {
+			existing.Depth = depth
+			if existing.Truncated {
+				log.Printf("%s: reached at depth %d, expanding again", n.ID, depth)
+				existing.Truncated = false
+				return b.expand(existing.ID, n.Title, meaning, depth, dir)
+			}
+		}
+		return nil
 	}
 
-	if i, ok := global[title]; ok && lang == wikt.Russian {
-		return i
+	if b.opts.MaxNodes > 0 && len(b.g.Nodes) >= b.opts.MaxNodes {
+		b.g.trace(e.Tooltip, n.Title, Limited, "node limit of %d reached", b.opts.MaxNodes)
+		b.truncate(p.t)
+		return nil
 	}
 
-	if len(meanings) > 1 && strings.HasPrefix(meanings[0].Value, "действие по значению гл.") {
-		return 1
+	n.Depth = p.depth + 1
+	n.Synonyms = meaning.Synonyms
+	b.g.AddNode(n)
+	b.senses[n.ID] = meaning
+	b.g.record(&Event{
+		Key:    e.Tooltip,
+		Title:  n.Title,
+		Action: Added,
+		Reason: fmt.Sprintf("node %s at depth %d", n.ID, n.Depth),
+		Node:   n,
+	})
+	b.g.AddEdge(e)
+	b.edgeAdded(e, n.Title)
+
+	if p.rel != "" {
+		b.side[n.ID] = true
+		b.relate(n.ID, meaning, n.Depth, p.rel, p.hops+1)
+		return nil
 	}
 
-	return 0
+	return b.grow(n, meaning, dir)
+}
+
+func (b *builder) edgeAdded(e *Edge, title string) {
+	b.g.record(&Event{
+		Key:    e.Tooltip,
+		Title:  title,
+		Action: Added,
+		Reason: fmt.Sprintf("edge %s -> %s [%s, %s], confidence %.2f", e.From, e.To, e.Relation, e.Kind, e.Confidence),
+		Edge:   e,
+	})
 }
 
-func addEdge(g *dot.Graph, from, to, tooltip string, kind kind, strict, polysemous bool) {
-	attrs := map[string]string{
-		"penwidth": "3",
-		"tooltip":  glue(tooltip),
+// grow expands the skeleton node n along hyperonymy, synonymy within synsets
+// and the enabled side relations.
+func (b *builder) grow(n *Node, meaning *parser.Meaning, dir Direction) error {
+	if err := b.expand(n.ID, n.Title, meaning, n.Depth, dir); err != nil {
+		return err
 	}
-	if !strict && polysemous {
-		switch kind {
-		case own:
-			attrs["color"] = "green"
-		case predicted:
-			attrs["color"] = "blue"
-		}
+
+	if err := b.synset(n, meaning, dir); err != nil {
+		return err
 	}
-	_ = g.AddEdge(glue(from), glue(to), true, attrs)
+
+	b.relate(n.ID, meaning, n.Depth, "", 0)
+	return nil
 }
 
-func predict(title, lang string, meaning *parser.Meaning, strict bool, presets map[string]int, existing []string, pool *redis.Pool) ([]string, []*predictedParams, error) {
-	var hs []string
-	var params []*predictedParams
-	for _, tru := range meaning.Translations.ByLanguage(wikt.Russian) {
-		w, err := GetWord(tru, pool)
+// synset merges into n the senses of its synonyms listing n back as a synonym,
+// the relations of the merged senses are expanded from n.
+func (b *builder) synset(n *Node, meaning *parser.Meaning, dir Direction) error {
+	if !b.opts.Synsets {
+		return nil
+	}
+
+	for _, s := range meaning.Synonyms {
+		if s == n.Title || contains(n.Lemmas, s) {
+			continue
+		}
+
+		word, err := b.fetch(s)
 		if err != nil {
 			if err == wikt.ErrMissing {
-				log.Println(tru, err)
+				b.g.trace(n.ID+"~"+s, s, Missing, "%v", err)
 				continue
 			}
-			return nil, nil, err
+			if err == errBudget {
+				b.g.trace(n.ID+"~"+s, s, Limited, "%v", err)
+				b.truncate(n.ID)
+				return nil
+			}
+			return err
 		}
 
+		meanings := word.ByLanguage(b.lang)
 		idx := -1
-		for i, mru := range w.ByLanguage(wikt.Russian) {
-			if contains(mru.Translations.ByLanguage(lang), title) {
+		for i, m := range meanings {
+			if contains(m.Synonyms, n.Title) {
 				idx = i
 				break
 			}
 		}
 		if idx == -1 {
+			b.g.trace(n.ID+"~"+s, s, Denied, "no sense of %s lists %s as a synonym", s, n.Title)
 			continue
 		}
 
-		for _, hru := range w.ByLanguage(wikt.Russian)[idx].Hyperonyms {
-			wh, err := GetWord(hru, pool)
-			if err != nil {
-				if err == wikt.ErrMissing {
-					log.Println(hru, err)
-					continue
-				}
-				return nil, nil, err
-			}
+		name := s
+		if len(meanings) > 1 {
+			name += fmt.Sprintf(":%d", idx)
+		}
+		if _, ok := b.aliases[name]; ok {
+			continue
+		}
 
-			idx2 := -1
-			tooltip := fmt.Sprintf("%s:%d->%s", tru, idx, hru)
-			if strict {
-				for i, mhru := range wh.ByLanguage(wikt.Russian) {
-					if contains(mhru.Hyponyms, tru) {
-						idx2 = i
-						break
-					}
-				}
-				if idx2 == -1 {
-					continue
+		if len(n.Lemmas) == 0 {
+			n.Lemmas = []string{n.Title}
+		}
+		if b.g.Node(name) != nil {
+			b.g.Merge(n.ID, name)
+			// The members of a synset merged away now belong to n.
+			for alias, id := range b.aliases {
+				if id == name {
+					b.aliases[alias] = n.ID
 				}
-			} else {
-				idx2 = index(tooltip, wikt.Russian, wh.ByLanguage(wikt.Russian), presets)
 			}
+		} else {
+			n.Lemmas = append(n.Lemmas, s)
+		}
+		b.aliases[name] = n.ID
+		b.g.trace(n.ID+"~"+s, s, Merged, "%s merged into %s", name, n.ID)
 
-			for _, t := range wh.ByLanguage(wikt.Russian)[idx2].Translations.ByLanguage(lang) {
-				if !contains(existing, t) && !contains(hs, t) {
-					hs = append(hs, t)
-					params = append(params, &predictedParams{
-						ru:         hru,
-						tooltip:    tooltip + ":" + strconv.Itoa(idx2),
-						polysemous: len(wh.ByLanguage(wikt.Russian)) > 1,
-					})
-				}
-			}
+		if err := b.expand(n.ID, s, meanings[idx], n.Depth, dir); err != nil {
+			return err
 		}
 	}
 
-	return hs, params, nil
+	return nil
 }
 
-func contains(strings []string, value string) bool {
-	for _, s := range strings {
-		if value == s {
-			return true
+// choose selects the sense of c through the selector chain of Options or the
+// default chain of the mode. Seed words fall back to the first sense,
+// other words are denied with -1 when every selector abstains.
+func (b *builder) choose(c *Candidate) (int, error) {
+	chain := b.opts.Selectors
+	if chain == nil {
+		if b.opts.Strict && c.From != nil {
+			chain = strictSelectors()
+		} else {
+			chain = naiveSelectors(b.opts.Presets)
 		}
 	}
 
-	return false
+	for _, s := range chain {
+		idx, reason, ok := s.Select(c)
+		if !ok {
+			continue
+		}
+		if l := len(c.Meanings); idx < 0 || idx >= l {
+			return 0, fmt.Errorf("[ERROR] некорректные параметры запроса для слова %s: запрошено значение %d (всего доступно %d)", c.Title, idx, l)
+		}
+		b.g.trace(c.Key, c.Title, Selected, "%d/%d by %s: %s", idx, len(c.Meanings), selectorName(s), reason)
+		return idx, nil
+	}
+
+	if c.From == nil {
+		b.g.trace(c.Key, c.Title, Selected, "0/%d: no selector fits, first sense of the seed word", len(c.Meanings))
+		return 0, nil
+	}
+	b.g.trace(c.Key, c.Title, Denied, "none of %d selectors picked a sense", len(chain))
+	return -1, nil
 }
 
-func glue(s string) string {
-	return fmt.Sprintf("\"%s\"", s)
+// candidate describes h reached from the node t through the chain key.
+func (b *builder) candidate(t, h, key string, meanings parser.Meanings, back func(*parser.Meaning) []string) *Candidate {
+	return &Candidate{
+		Key:      key,
+		Title:    h,
+		Lang:     b.lang,
+		Meanings: meanings,
+		From:     b.senses[t],
+		Lemmas:   b.lemmas(t),
+		Back:     back,
+	}
 }
 
-func GetWord(title string, pool *redis.Pool) (parser.Word, error) {
-	const datePrefix = "date:"
-	const wordPrefix = "word:"
+func hyperonyms(m *parser.Meaning) []string {
+	return m.Hyperonyms
+}
 
-	c := pool.Get()
-	defer c.Close()
+func hyponyms(m *parser.Meaning) []string {
+	return m.Hyponyms
+}
 
-	update := func(date string) (parser.Word, error) {
-		word, err := parser.Parse(title)
-		if err != nil {
-			return nil, err
-		}
+func synonyms(m *parser.Meaning) []string {
+	return m.Synonyms
+}
 
-		data, err := json.Marshal(word)
-		if err != nil {
-			return nil, err
-		}
+// lemmas returns the words standing behind the node name.
+func (b *builder) lemmas(name string) []string {
+	if n := b.g.Node(name); n != nil && len(n.Lemmas) > 0 {
+		return n.Lemmas
+	}
 
-		_, err = c.Do("SET", datePrefix+title, date)
-		if err != nil {
-			return nil, err
-		}
+	return []string{strings.Split(name, ":")[0]}
+}
 
-		_, err = c.Do("SET", wordPrefix+title, data)
-		if err != nil {
-			return nil, err
-		}
+// expand schedules the neighbours of the node name found at the given depth:
+// hyperonyms when going up, hyponyms when going down.
+func (b *builder) expand(name, title string, meaning *parser.Meaning, depth int, dir Direction) error {
+	up := dir != Down
+	down := dir == Down || dir == Both
 
-		return word, nil
+	if b.opts.MaxDepth > 0 && depth >= b.opts.MaxDepth {
+		if up && (len(meaning.Hyperonyms) > 0 || b.lang != wikt.Russian) || down && len(meaning.Hyponyms) > 0 {
+			b.g.trace(name, title, Limited, "depth limit of %d reached", b.opts.MaxDepth)
+			b.truncate(name)
+		}
+		return nil
 	}
 
-	dateWikiStr, err := wikt.GetLastRevision(title)
-	if err != nil {
-		return nil, err
+	if down && len(meaning.Hyponyms) > 0 {
+		b.stack.pushDown(name, depth, meaning.Hyponyms)
+		log.Printf("%s hyponyms: %s", name, meaning.Hyponyms)
+	}
+	if !up {
+		return nil
 	}
 
-	dateRedisStr, err := redis.String(c.Do("GET", datePrefix+title))
-	if err != nil {
-		if err == redis.ErrNil {
-			return update(dateWikiStr)
+	if len(meaning.Hyperonyms) > 0 {
+		b.stack.push(name, depth, meaning.Hyperonyms)
+		log.Printf("%s own: %s", name, meaning.Hyperonyms)
+	}
+	if b.lang != wikt.Russian {
+		hs, pp, err := b.predict(title, b.lang, meaning, meaning.Hyperonyms)
+		if err == errBudget {
+			b.truncate(name)
+		} else if err != nil {
+			return err
+		}
+		if len(hs) > 0 {
+			b.stack.push2(name, depth, hs, pp)
+			log.Printf("%s predicted: %s", name, hs)
 		}
 	}
 
-	dateWiki, err := time.Parse(time.RFC3339, dateWikiStr)
-	if err != nil {
-		return nil, err
+	return nil
+}
+
+func (b *builder) fetch(title string) (parser.Word, error) {
+	if b.opts.MaxFetches > 0 && b.fetches >= b.opts.MaxFetches {
+		return nil, errBudget
 	}
+	b.fetches++
 
-	dateRedis, err := time.Parse(time.RFC3339, dateRedisStr)
+	b.g.report(&Event{Key: title, Title: title, Action: Fetching})
+	word, err := GetWord(title, b.pool)
+	done := &Event{Key: title, Title: title, Action: Fetched}
 	if err != nil {
-		return nil, err
+		done.Reason = err.Error()
 	}
+	b.g.report(done)
+
+	return word, err
+}
 
-	if dateWiki.Sub(dateRedis) > 0 {
-		return update(dateWikiStr)
+func (b *builder) truncate(name string) {
+	if n := b.g.Node(name); n != nil {
+		n.Truncated = true
 	}
+	b.g.Truncated = true
+}
 
-	s, err := redis.String(c.Do("GET", wordPrefix+title))
-	if err != nil {
-		if err == redis.ErrNil {
-			return update(dateWikiStr)
+// predict translates the hyperonyms of the translations of meaning into
+// every pivot language back into lang. Predictions are combined by voting:
+// each is scored by the share of pivot translations agreeing on it.
+func (b *builder) predict(title, lang string, meaning *parser.Meaning, existing []string) ([]string, []*predictedParams, error) {
+	var hs []string
+	var params []*predictedParams
+	var pivots int
+	votes := make(map[string]map[string]bool)
+	paths := make(map[string][]string)
+	defer func() {
+		for i, h := range hs {
+			params[i].confidence = predicted(len(votes[h]), pivots)
+			params[i].pivots = paths[h]
+		}
+	}()
+
+	for _, pivot := range b.pivots() {
+		if pivot == lang {
+			continue
+		}
+
+		for _, tp := range meaning.Translations.ByLanguage(pivot) {
+			w, err := b.fetch(tp)
+			if err != nil {
+				if err == wikt.ErrMissing {
+					b.g.trace(tp, tp, Missing, "%v", err)
+					continue
+				}
+				if err == errBudget {
+					return hs, params, err
+				}
+				return nil, nil, err
+			}
+
+			idx := -1
+			for i, mp := range w.ByLanguage(pivot) {
+				if contains(mp.Translations.ByLanguage(lang), title) {
+					idx = i
+					break
+				}
+			}
+			if idx == -1 {
+				continue
+			}
+			pivots++
+
+			for _, hp := range w.ByLanguage(pivot)[idx].Hyperonyms {
+				wh, err := b.fetch(hp)
+				if err != nil {
+					if err == wikt.ErrMissing {
+						b.g.trace(hp, hp, Missing, "%v", err)
+						continue
+					}
+					if err == errBudget {
+						return hs, params, err
+					}
+					return nil, nil, err
+				}
+
+				tooltip := fmt.Sprintf("%s:%d->%s", tp, idx, hp)
+				meanings := wh.ByLanguage(pivot)
+				idx2, err := b.choose(&Candidate{
+					Key:      tooltip,
+					Title:    hp,
+					Lang:     pivot,
+					Meanings: meanings,
+					From:     w.ByLanguage(pivot)[idx],
+					Lemmas:   []string{tp},
+					Back:     hyponyms,
+				})
+				if err != nil {
+					return nil, nil, err
+				}
+				if idx2 == -1 {
+					continue
+				}
+				tooltip += ":" + strconv.Itoa(idx2)
+
+				for _, t := range meanings[idx2].Translations.ByLanguage(lang) {
+					if votes[t] == nil {
+						votes[t] = make(map[string]bool)
+					}
+					votes[t][pivot+":"+tp] = true
+					paths[t] = append(paths[t], fmt.Sprintf("%s:%s->%s:%s->%s", lang, title, pivot, tooltip, t))
+					if !contains(existing, t) && !contains(hs, t) {
+						hs = append(hs, t)
+						params = append(params, &predictedParams{
+							pivot:      pivot,
+							h:          hp,
+							tooltip:    tooltip,
+							polysemous: len(meanings) > 1,
+						})
+					}
+				}
+			}
+		}
+	}
+
+	return hs, params, nil
+}
+
+// pivots lists the languages predictions go through, Russian by default.
+func (b *builder) pivots() []string {
+	if len(b.opts.Pivots) == 0 {
+		return []string{wikt.Russian}
+	}
+
+	return b.opts.Pivots
+}
+
+func contains(strings []string, value string) bool {
+	for _, s := range strings {
+		if value == s {
+			return true
 		}
-		return nil, err
 	}
 
-	word := parser.Word{}
-	err = json.Unmarshal([]byte(s), &word)
+	return false
+}
 
-	return word, nil
+func containsAny(strings []string, values []string) bool {
+	for _, v := range values {
+		if contains(strings, v) {
+			return true
+		}
+	}
+
+	return false
 }
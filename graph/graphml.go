@@ -0,0 +1,85 @@
+package graph
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+const graphMLNamespace = "http://graphml.graphdrawing.org/xmlns"
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	ID     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// GraphML renders the taxonomy as a GraphML document, node and edge
+// attributes are declared as keys prefixed with n_ and e_.
+func (t *Taxonomy) GraphML() ([]byte, error) {
+	doc := graphML{
+		XMLNS: graphMLNamespace,
+		Graph: graphMLGraph{ID: t.Name, EdgeDefault: "directed"},
+	}
+
+	for _, a := range nodeAttributes {
+		doc.Keys = append(doc.Keys, graphMLKey{ID: "n_" + a.Name, For: "node", Name: a.Name, Type: a.Type})
+	}
+	for _, a := range edgeAttributes {
+		doc.Keys = append(doc.Keys, graphMLKey{ID: "e_" + a.Name, For: "edge", Name: a.Name, Type: a.Type})
+	}
+
+	for _, n := range t.Nodes {
+		node := graphMLNode{ID: n.ID}
+		for _, a := range nodeAttributes {
+			node.Data = append(node.Data, graphMLData{Key: "n_" + a.Name, Value: fmt.Sprint(a.Value(n))})
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, node)
+	}
+
+	for i, e := range t.Edges {
+		edge := graphMLEdge{ID: fmt.Sprintf("e%d", i), Source: e.From, Target: e.To}
+		for _, a := range edgeAttributes {
+			edge.Data = append(edge.Data, graphMLData{Key: "e_" + a.Name, Value: fmt.Sprint(a.Value(e))})
+		}
+		doc.Graph.Edges = append(doc.Graph.Edges, edge)
+	}
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), data...), nil
+}
@@ -0,0 +1,284 @@
+package graph
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+const (
+	jobPrefix    = "job:"
+	resultPrefix = "result:"
+	leasePrefix  = "lease:"
+	queueKey     = "jobs:queue"
+
+	// jobTTL bounds the life of jobs and their results, so that
+	// rebuilds pick up the edits of Wiktionary.
+	jobTTL = 24 * time.Hour
+	// jobLease is how long a running job outlives its worker,
+	// which renews the lease every jobHeartbeat.
+	jobLease     = time.Minute
+	jobHeartbeat = jobLease / 3
+)
+
+var ErrNoJob = errors.New("job not found")
+
+type JobStatus string
+
+const (
+	Queued  JobStatus = "queued"
+	Running JobStatus = "running"
+	Done    JobStatus = "done"
+	Failed  JobStatus = "failed"
+)
+
+// BuildRequest describes a build run as a job, identical requests share a job.
+type BuildRequest struct {
+	Titles  []string       `json:"titles"`
+	Lang    string         `json:"lang"`
+	Strict  bool           `json:"strict"`
+	Presets map[string]int `json:"presets"`
+}
+
+// id hashes r regardless of the order of titles,
+// encoding/json sorts the keys of presets.
+func (r BuildRequest) id() string {
+	titles := append([]string(nil), r.Titles...)
+	sort.Strings(titles)
+	if r.Presets == nil {
+		r.Presets = map[string]int{}
+	}
+
+	data, _ := json.Marshal(BuildRequest{Titles: titles, Lang: r.Lang, Strict: r.Strict, Presets: r.Presets})
+	sum := sha1.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
+type Job struct {
+	ID      string       `json:"id"`
+	Status  JobStatus    `json:"status"`
+	Request BuildRequest `json:"request"`
+	Error   string       `json:"error,omitempty"`
+	Created time.Time    `json:"created"`
+	Updated time.Time    `json:"updated"`
+}
+
+// Submit queues a build job for r unless a job for an identical request
+// is queued, running or done already. Failed jobs are queued again, so are
+// running ones whose lease expired as their worker died.
+func Submit(r BuildRequest, pool *redis.Pool) (*Job, error) {
+	c := pool.Get()
+	defer c.Close()
+
+	now := time.Now().UTC()
+	job := &Job{ID: r.id(), Status: Queued, Request: r, Created: now, Updated: now}
+	data, err := json.Marshal(job)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = redis.String(c.Do("SET", jobPrefix+job.ID, data, "NX", "EX", int(jobTTL.Seconds())))
+	if err == redis.ErrNil {
+		existing, err := getJob(c, job.ID)
+		if err != nil {
+			return nil, err
+		}
+		err = reap(c, existing)
+		if err != nil {
+			return nil, err
+		}
+		if existing.Status != Failed {
+			return existing, nil
+		}
+		err = saveJob(c, job)
+		if err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	_, err = c.Do("RPUSH", queueKey, job.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// GetJob returns the job id, failing it first if its worker died.
+func GetJob(id string, pool *redis.Pool) (*Job, error) {
+	c := pool.Get()
+	defer c.Close()
+
+	job, err := getJob(c, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return job, reap(c, job)
+}
+
+// JobResult returns the taxonomy built by the job id as JSON.
+func JobResult(id string, pool *redis.Pool) ([]byte, error) {
+	c := pool.Get()
+	defer c.Close()
+
+	data, err := redis.Bytes(c.Do("GET", resultPrefix+id))
+	if err == redis.ErrNil {
+		return nil, ErrNoJob
+	}
+
+	return data, err
+}
+
+// Work runs the next queued job, waiting for one up to timeout.
+func Work(timeout time.Duration, pool *redis.Pool) error {
+	c := pool.Get()
+	values, err := redis.Strings(c.Do("BLPOP", queueKey, int(timeout.Seconds())))
+	c.Close()
+	if err == redis.ErrNil {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return run(values[1], pool)
+}
+
+// run builds the job id, a panicking build fails the job.
+// The lease of the job is renewed until the build ends.
+func run(id string, pool *redis.Pool) (err error) {
+	c := pool.Get()
+	defer c.Close()
+
+	job, err := getJob(c, id)
+	if err == ErrNoJob {
+		log.Printf("job %s expired", id)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if job.Status != Queued {
+		return nil
+	}
+
+	if err = renew(c, id); err != nil {
+		return err
+	}
+	job.Status = Running
+	if err = saveJob(c, job); err != nil {
+		return err
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+	go heartbeat(id, stop, pool)
+	defer func() {
+		if r := recover(); r != nil {
+			job.Status, job.Error = Failed, fmt.Sprint(r)
+			err = saveJob(c, job)
+		}
+	}()
+
+	log.Printf("job %s: building %s (%s)", id, job.Request.Titles, job.Request.Lang)
+	t, err := Build(job.Request.Titles, job.Request.Lang, Options{
+		Strict:  job.Request.Strict,
+		Presets: job.Request.Presets,
+	}, pool)
+	if err != nil {
+		job.Status, job.Error = Failed, err.Error()
+		return saveJob(c, job)
+	}
+
+	data, err := json.Marshal(t)
+	if err == nil {
+		_, err = c.Do("SET", resultPrefix+id, data, "EX", int(jobTTL.Seconds()))
+	}
+	if err != nil {
+		job.Status, job.Error = Failed, err.Error()
+		if serr := saveJob(c, job); serr != nil {
+			return serr
+		}
+		return err
+	}
+
+	job.Status = Done
+	return saveJob(c, job)
+}
+
+// heartbeat renews the lease of the job id every jobHeartbeat until stop is closed.
+func heartbeat(id string, stop <-chan struct{}, pool *redis.Pool) {
+	ticker := time.NewTicker(jobHeartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			c := pool.Get()
+			if err := renew(c, id); err != nil {
+				log.Printf("job %s: %v", id, err)
+			}
+			c.Close()
+		}
+	}
+}
+
+// reap fails job if it is running without a lease, as its worker died.
+func reap(c redis.Conn, job *Job) error {
+	if job.Status != Running {
+		return nil
+	}
+
+	alive, err := redis.Bool(c.Do("EXISTS", leasePrefix+job.ID))
+	if err != nil || alive {
+		return err
+	}
+
+	job.Status, job.Error = Failed, "build worker stopped"
+	return saveJob(c, job)
+}
+
+func renew(c redis.Conn, id string) error {
+	_, err := c.Do("SET", leasePrefix+id, 1, "EX", int(jobLease.Seconds()))
+	return err
+}
+
+func getJob(c redis.Conn, id string) (*Job, error) {
+	data, err := redis.Bytes(c.Do("GET", jobPrefix+id))
+	if err != nil {
+		if err == redis.ErrNil {
+			return nil, ErrNoJob
+		}
+		return nil, err
+	}
+
+	job := &Job{}
+	err = json.Unmarshal(data, job)
+	if err != nil {
+		return nil, fmt.Errorf("job %s: %v", id, err)
+	}
+
+	return job, nil
+}
+
+func saveJob(c redis.Conn, job *Job) error {
+	job.Updated = time.Now().UTC()
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.Do("SET", jobPrefix+job.ID, data, "EX", int(jobTTL.Seconds()))
+	return err
+}
@@ -0,0 +1,210 @@
+package graph
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+var ErrNoOverride = errors.New("override not found")
+
+// Override fixes the sense of a recurring word for every build.
+type Override struct {
+	Sense  int       `json:"sense"`
+	Reason string    `json:"reason"`
+	Author string    `json:"author"`
+	Date   time.Time `json:"date"`
+}
+
+const (
+	overridesKey   = "overrides"
+	overridePrefix = "override:"
+)
+
+// overrides caches the overrides stored in Redis, a hash per language
+// mapping titles to their overrides. The languages make up the set overridesKey.
+type overrides struct {
+	sync.RWMutex
+	langs map[string]map[string]Override
+}
+
+var global = &overrides{langs: make(map[string]map[string]Override)}
+
+// LoadOverrides reads the overrides from Redis. Files of dir named {lang}.json
+// seed the languages missing from Redis, a missing dir holds no seeds.
+func LoadOverrides(dir string, pool *redis.Pool) error {
+	c := pool.Get()
+	defer c.Close()
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		err = seed(c, f)
+		if err != nil {
+			return err
+		}
+	}
+
+	langs, err := redis.Strings(c.Do("SMEMBERS", overridesKey))
+	if err != nil {
+		return err
+	}
+
+	o := &overrides{langs: make(map[string]map[string]Override)}
+	for _, lang := range langs {
+		values, err := redis.StringMap(c.Do("HGETALL", overridePrefix+lang))
+		if err != nil {
+			return err
+		}
+
+		o.langs[lang] = make(map[string]Override)
+		for title, data := range values {
+			var v Override
+			err = json.Unmarshal([]byte(data), &v)
+			if err != nil {
+				return fmt.Errorf("override %s (%s): %v", title, lang, err)
+			}
+			o.langs[lang][title] = v
+		}
+	}
+
+	global.Lock()
+	defer global.Unlock()
+	global.langs = o.langs
+
+	return nil
+}
+
+// seed stores the overrides of the file f unless its language is already in Redis,
+// so that overrides changed or deleted later are not restored.
+func seed(c redis.Conn, f string) error {
+	data, err := ioutil.ReadFile(f)
+	if err != nil {
+		return err
+	}
+
+	var values map[string]Override
+	err = json.Unmarshal(data, &values)
+	if err != nil {
+		return fmt.Errorf("%s: %v", f, err)
+	}
+
+	lang := strings.TrimSuffix(filepath.Base(f), ".json")
+	added, err := redis.Int(c.Do("SADD", overridesKey, lang))
+	if err != nil || added == 0 {
+		return err
+	}
+
+	for title, o := range values {
+		data, err := json.Marshal(o)
+		if err != nil {
+			return err
+		}
+		_, err = c.Do("HSETNX", overridePrefix+lang, title, data)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Overrides returns a copy of the overrides of lang.
+func Overrides(lang string) map[string]Override {
+	global.RLock()
+	defer global.RUnlock()
+
+	res := make(map[string]Override)
+	for title, o := range global.langs[lang] {
+		res[title] = o
+	}
+
+	return res
+}
+
+func override(title, lang string) (Override, bool) {
+	global.RLock()
+	defer global.RUnlock()
+
+	o, ok := global.langs[lang][title]
+	return o, ok
+}
+
+// SetOverride stores the override of title in lang.
+func SetOverride(title, lang string, o Override, pool *redis.Pool) error {
+	if err := checkLang(lang); err != nil {
+		return err
+	}
+	if o.Sense < 0 {
+		return errors.New("sense must not be negative")
+	}
+	if o.Reason == "" || o.Author == "" {
+		return errors.New("reason and author are required")
+	}
+	if o.Date.IsZero() {
+		o.Date = time.Now().UTC()
+	}
+
+	data, err := json.Marshal(o)
+	if err != nil {
+		return err
+	}
+
+	c := pool.Get()
+	defer c.Close()
+
+	_, err = c.Do("SADD", overridesKey, lang)
+	if err != nil {
+		return err
+	}
+	_, err = c.Do("HSET", overridePrefix+lang, title, data)
+	if err != nil {
+		return err
+	}
+
+	global.Lock()
+	defer global.Unlock()
+	if global.langs[lang] == nil {
+		global.langs[lang] = make(map[string]Override)
+	}
+	global.langs[lang][title] = o
+
+	return nil
+}
+
+// DeleteOverride removes the override of title in lang.
+func DeleteOverride(title, lang string, pool *redis.Pool) error {
+	c := pool.Get()
+	defer c.Close()
+
+	n, err := redis.Int(c.Do("HDEL", overridePrefix+lang, title))
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNoOverride
+	}
+
+	global.Lock()
+	defer global.Unlock()
+	delete(global.langs[lang], title)
+
+	return nil
+}
+
+func checkLang(lang string) error {
+	if lang == "" || lang != filepath.Base(lang) || strings.HasPrefix(lang, ".") {
+		return errors.New("invalid language " + lang)
+	}
+
+	return nil
+}
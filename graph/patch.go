@@ -0,0 +1,196 @@
+package graph
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+const patchPrefix = "patch:"
+
+type PatchOp string
+
+const (
+	DeleteEdge PatchOp = "delete-edge"
+	AddEdge    PatchOp = "add-edge"
+	AddNode    PatchOp = "add-node"
+	MergeNodes PatchOp = "merge-nodes"
+)
+
+var ErrNoPatch = errors.New("patch entry not found")
+
+// PatchEntry is a manual correction of a built taxonomy. Edges go From To
+// along Relation, hyperonymy by default, merges fold From into To,
+// added nodes take ID, Title and Meaning.
+type PatchEntry struct {
+	Number   int       `json:"number"`
+	Op       PatchOp   `json:"op"`
+	From     string    `json:"from,omitempty"`
+	To       string    `json:"to,omitempty"`
+	Relation Relation  `json:"relation,omitempty"`
+	ID       string    `json:"id,omitempty"`
+	Title    string    `json:"title,omitempty"`
+	Meaning  string    `json:"meaning,omitempty"`
+	Author   string    `json:"author"`
+	Reason   string    `json:"reason"`
+	Created  time.Time `json:"created"`
+}
+
+func (p *PatchEntry) validate() error {
+	switch p.Op {
+	case DeleteEdge, AddEdge, MergeNodes:
+		if p.From == "" || p.To == "" || p.From == p.To {
+			return fmt.Errorf("%s needs distinct from and to", p.Op)
+		}
+	case AddNode:
+		if p.ID == "" {
+			return fmt.Errorf("%s needs an id", p.Op)
+		}
+	default:
+		return fmt.Errorf("unknown patch op %q", p.Op)
+	}
+	if p.Author == "" {
+		return errors.New("author is required")
+	}
+
+	return nil
+}
+
+// Patch is an ordered list of corrections reapplied after every build.
+type Patch []*PatchEntry
+
+// Apply performs the entries of p in order. Entries referring to nodes
+// or edges missing from t, e.g. after a sense change, are skipped and traced.
+func (t *Taxonomy) Apply(p Patch) {
+	for _, e := range p {
+		relation := e.Relation
+		if relation == "" {
+			relation = Hyperonymy
+		}
+		chain := fmt.Sprintf("%s->%s", e.From, e.To)
+		by := fmt.Sprintf("%s by %s: %s", e.Op, e.Author, e.Reason)
+		switch e.Op {
+		case DeleteEdge:
+			edge := t.Edge(e.From, e.To, relation)
+			if edge == nil {
+				t.trace(chain, e.To, Missing, "%s, edge not found", by)
+				continue
+			}
+			t.RemoveEdge(e.From, e.To, relation)
+			t.trace(edge.Tooltip, e.To, Dropped, "%s", by)
+		case AddEdge:
+			if t.Node(e.From) == nil || t.Node(e.To) == nil {
+				t.trace(chain, e.To, Missing, "%s, nodes not found", by)
+				continue
+			}
+			if t.Edge(e.From, e.To, relation) != nil {
+				continue
+			}
+			edge := &Edge{
+				From:     e.From,
+				To:       e.To,
+				Relation: relation,
+				Kind:     Curated,
+				Provenance: Provenance{
+					Tooltip:    chain,
+					Strict:     true,
+					Confidence: ConfidenceBackLink,
+				},
+			}
+			t.AddEdge(edge)
+			t.record(&Event{Key: chain, Title: e.To, Action: Added, Reason: by, Edge: edge})
+		case AddNode:
+			if t.Node(e.ID) != nil {
+				t.trace(e.ID, e.Title, Denied, "%s, node exists", by)
+				continue
+			}
+			title := e.Title
+			if title == "" {
+				title = strings.Split(e.ID, ":")[0]
+			}
+			n := &Node{ID: e.ID, Title: title, Lang: t.Lang, Meaning: e.Meaning, Curated: true}
+			t.AddNode(n)
+			t.record(&Event{Key: e.ID, Title: title, Action: Added, Reason: by, Node: n})
+		case MergeNodes:
+			if t.Node(e.From) == nil || t.Node(e.To) == nil {
+				t.trace(chain, e.To, Missing, "%s, nodes not found", by)
+				continue
+			}
+			t.Merge(e.To, e.From)
+			t.trace(e.To, e.To, Merged, "%s, %s merged", by, e.From)
+		}
+	}
+}
+
+func GetPatch(name string, pool *redis.Pool) (Patch, error) {
+	c := pool.Get()
+	defer c.Close()
+
+	values, err := redis.ByteSlices(c.Do("LRANGE", patchPrefix+name, 0, -1))
+	if err != nil {
+		return nil, err
+	}
+
+	p := Patch{}
+	for i, data := range values {
+		e := &PatchEntry{}
+		err = json.Unmarshal(data, e)
+		if err != nil {
+			return nil, fmt.Errorf("patch %s: %v", name, err)
+		}
+		e.Number = i + 1
+		p = append(p, e)
+	}
+
+	return p, nil
+}
+
+// AddPatch appends e to the patch of the saved taxonomy name.
+func AddPatch(name string, e *PatchEntry, pool *redis.Pool) error {
+	if err := e.validate(); err != nil {
+		return err
+	}
+	e.Number = 0
+	e.Created = time.Now().UTC()
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	c := pool.Get()
+	defer c.Close()
+
+	n, err := redis.Int(c.Do("RPUSH", patchPrefix+name, data))
+	if err != nil {
+		return err
+	}
+
+	e.Number = n
+	return nil
+}
+
+// RemovePatch removes the entry n, counting from 1, of the patch of the saved taxonomy name.
+func RemovePatch(name string, n int, pool *redis.Pool) error {
+	if n < 1 {
+		return ErrNoPatch
+	}
+
+	c := pool.Get()
+	defer c.Close()
+
+	data, err := redis.Bytes(c.Do("LINDEX", patchPrefix+name, n-1))
+	if err == redis.ErrNil {
+		return ErrNoPatch
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = c.Do("LREM", patchPrefix+name, 1, data)
+	return err
+}
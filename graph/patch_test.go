@@ -0,0 +1,167 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name  string
+		edges []string
+		patch Patch
+		// wantEdges and wantNodes are the edges and nodes after Apply.
+		wantEdges []string
+		wantNodes []string
+		// skipped is the action traced for the last entry when it does not apply.
+		skipped Action
+	}{
+		{
+			name:      "delete edge",
+			edges:     []string{"кот->кошка", "кошка->животное"},
+			patch:     Patch{{Op: DeleteEdge, From: "кот", To: "кошка"}},
+			wantEdges: []string{"кошка->животное"},
+			wantNodes: []string{"кот", "кошка", "животное"},
+		},
+		{
+			name:      "delete missing edge",
+			edges:     []string{"кот->кошка"},
+			patch:     Patch{{Op: DeleteEdge, From: "кот", To: "животное"}},
+			wantEdges: []string{"кот->кошка"},
+			wantNodes: []string{"кот", "кошка"},
+			skipped:   Missing,
+		},
+		{
+			name:      "add edge",
+			edges:     []string{"кот->кошка", "кошка->животное"},
+			patch:     Patch{{Op: AddEdge, From: "кот", To: "животное"}},
+			wantEdges: []string{"кот->кошка", "кошка->животное", "кот->животное"},
+			wantNodes: []string{"кот", "кошка", "животное"},
+		},
+		{
+			name:      "add edge to missing node",
+			edges:     []string{"кот->кошка"},
+			patch:     Patch{{Op: AddEdge, From: "кот", To: "зверь"}},
+			wantEdges: []string{"кот->кошка"},
+			wantNodes: []string{"кот", "кошка"},
+			skipped:   Missing,
+		},
+		{
+			name:  "add node then edge",
+			edges: []string{"кот->кошка"},
+			patch: Patch{
+				{Op: AddNode, ID: "зверь:1"},
+				{Op: AddEdge, From: "кошка", To: "зверь:1"},
+			},
+			wantEdges: []string{"кот->кошка", "кошка->зверь:1"},
+			wantNodes: []string{"кот", "кошка", "зверь:1"},
+		},
+		{
+			name:  "add edge before its node",
+			edges: []string{"кот->кошка"},
+			patch: Patch{
+				{Op: AddEdge, From: "кошка", To: "зверь"},
+				{Op: AddNode, ID: "зверь"},
+			},
+			wantEdges: []string{"кот->кошка"},
+			wantNodes: []string{"кот", "кошка", "зверь"},
+		},
+		{
+			name:      "add existing node",
+			edges:     []string{"кот->кошка"},
+			patch:     Patch{{Op: AddNode, ID: "кошка", Meaning: "другое"}},
+			wantEdges: []string{"кот->кошка"},
+			wantNodes: []string{"кот", "кошка"},
+			skipped:   Denied,
+		},
+		{
+			name:      "merge nodes",
+			edges:     []string{"кот->кошка", "пёс->кошка:1", "кошка:1->животное"},
+			patch:     Patch{{Op: MergeNodes, From: "кошка:1", To: "кошка"}},
+			wantEdges: []string{"кот->кошка", "пёс->кошка", "кошка->животное"},
+			wantNodes: []string{"кот", "кошка", "пёс", "животное"},
+		},
+		{
+			name:      "merge missing node",
+			edges:     []string{"кот->кошка"},
+			patch:     Patch{{Op: MergeNodes, From: "кошка:1", To: "кошка"}},
+			wantEdges: []string{"кот->кошка"},
+			wantNodes: []string{"кот", "кошка"},
+			skipped:   Missing,
+		},
+		{
+			name:  "merge then delete the redirected edge",
+			edges: []string{"кот->кошка:1", "кошка:1->животное"},
+			patch: Patch{
+				{Op: MergeNodes, From: "кошка:1", To: "кошка"},
+				{Op: AddNode, ID: "кошка"},
+				{Op: MergeNodes, From: "кошка:1", To: "кошка"},
+				{Op: DeleteEdge, From: "кот", To: "кошка"},
+			},
+			wantEdges: []string{"кошка->животное"},
+			wantNodes: []string{"кот", "животное", "кошка"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := taxonomyOf(tt.edges...)
+			g.Apply(tt.patch)
+
+			if got := chains(g.Edges); !reflect.DeepEqual(got, tt.wantEdges) {
+				t.Errorf("edges = %v, want %v", got, tt.wantEdges)
+			}
+			if got := ids(g.Nodes); !reflect.DeepEqual(got, tt.wantNodes) {
+				t.Errorf("nodes = %v, want %v", got, tt.wantNodes)
+			}
+			if tt.skipped != "" {
+				if last := g.Trace[len(g.Trace)-1]; last.Action != tt.skipped {
+					t.Errorf("last event = %+v, want %s", last, tt.skipped)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyMarksCurated(t *testing.T) {
+	g := taxonomyOf("кот->кошка")
+	g.Apply(Patch{
+		{Op: AddNode, ID: "зверь"},
+		{Op: AddEdge, From: "кошка", To: "зверь", Relation: Synonymy},
+	})
+
+	if n := g.Node("зверь"); n == nil || !n.Curated || n.Title != "зверь" || n.Lang != g.Lang {
+		t.Errorf("added node = %+v", n)
+	}
+	e := g.Edge("кошка", "зверь", Synonymy)
+	if e == nil || e.Kind != Curated || e.Confidence != ConfidenceBackLink {
+		t.Errorf("added edge = %+v", e)
+	}
+	if g.Edge("кошка", "зверь", Hyperonymy) != nil {
+		t.Error("synonymy entry added a hyperonymy edge")
+	}
+}
+
+func TestApplyThenFilter(t *testing.T) {
+	g := taxonomyOf("кот->кошка", "кошка->животное", "пёс->собака")
+	g.Node("кот").Root = true
+	g.Edge("кот", "кошка", Hyperonymy).Confidence = 1
+	g.Edge("кошка", "животное", Hyperonymy).Confidence = 0.3
+	g.Edge("пёс", "собака", Hyperonymy).Confidence = 1
+
+	g.Apply(Patch{
+		{Op: AddNode, ID: "зверь"},
+		{Op: AddEdge, From: "животное", To: "зверь"},
+		{Op: AddEdge, From: "кошка", To: "зверь"},
+	})
+	g.Filter(0.5)
+
+	// животное stays linked to the curated зверь, пёс and собака to neither
+	// a seed word nor a curated node.
+	if got, want := ids(g.Nodes), []string{"кот", "кошка", "животное", "зверь"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("nodes = %v, want %v", got, want)
+	}
+	if got, want := chains(g.Edges), []string{"кот->кошка", "животное->зверь", "кошка->зверь"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("edges = %v, want %v", got, want)
+	}
+}
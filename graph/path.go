@@ -0,0 +1,306 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gomodule/redigo/redis"
+
+	"github.com/stillpiercer/wikitologies/parser"
+	wikt "github.com/stillpiercer/wikitologies/wiktionary"
+)
+
+const (
+	defaultPathLength  = 6
+	defaultPathFetches = 300
+)
+
+// Path is a chain of senses leading from one word to another,
+// Edges[i] links Nodes[i] to Nodes[i+1].
+type Path struct {
+	From  string  `json:"from"`
+	To    string  `json:"to"`
+	Nodes []*Node `json:"nodes"`
+	Edges []*Edge `json:"edges"`
+}
+
+// ErrNoPath is wrapped by the errors of searches that ended without a path,
+// including those that ran out of their fetch budget.
+var ErrNoPath = errors.New("путь не найден")
+
+type vertex struct {
+	node    *Node
+	meaning *parser.Meaning
+	prev    *vertex
+	edge    *Edge
+	length  int
+}
+
+// hop is a relation followed from a sense, back lists the words
+// a target sense has to link back with in strict mode.
+type hop struct {
+	rel    Relation
+	titles []string
+	format string
+	back   func(*parser.Meaning) []string
+}
+
+func (v *vertex) key() string {
+	return v.node.Lang + "|" + v.node.ID
+}
+
+// FindPath searches breadth-first for the shortest path from from@fromLang
+// to to@toLang through hyperonyms and hyponyms, own and predicted ones.
+// Synonyms and translations are followed when enabled in opts.Relations.
+// MaxDepth bounds the path length and MaxFetches the search,
+// both have defaults as the search is not bounded otherwise. Searches ending
+// without a path fail with ErrNoPath, unknown source words with wikt.ErrMissing.
+func FindPath(from, fromLang, to, toLang string, opts Options, pool *redis.Pool) (*Path, error) {
+	if opts.MaxDepth == 0 {
+		opts.MaxDepth = defaultPathLength
+	}
+	if opts.MaxFetches == 0 {
+		opts.MaxFetches = defaultPathFetches
+	}
+
+	b := &builder{
+		lang:    fromLang,
+		opts:    opts,
+		pool:    pool,
+		g:       NewTaxonomy("", fromLang),
+		aliases: make(map[string]string),
+		side:    make(map[string]bool),
+	}
+	notFound := fmt.Errorf("%w: от %s (%s) до %s (%s)", ErrNoPath, from, fromLang, to, toLang)
+
+	meanings, err := b.meanings(from, fromLang)
+	if err != nil {
+		return nil, err
+	}
+	if meanings == nil {
+		return nil, fmt.Errorf("слово %s (%s) не найдено: %w", from, fromLang, wikt.ErrMissing)
+	}
+	idx, err := b.choose(&Candidate{Key: from, Title: from, Lang: fromLang, Meanings: meanings})
+	if err != nil {
+		return nil, err
+	}
+
+	start := &vertex{node: newNode(from, fromLang, idx, meanings), meaning: meanings[idx]}
+	start.node.Root = true
+	visited := map[string]bool{start.key(): true}
+	queue := []*vertex{start}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		if v.node.Title == to && v.node.Lang == toLang {
+			return v.path(from, to), nil
+		}
+		if v.length >= opts.MaxDepth {
+			continue
+		}
+
+		next, err := b.neighbours(v, fromLang, toLang)
+		if err == errBudget {
+			return nil, fmt.Errorf("%w: превышен лимит в %d запросов", notFound, opts.MaxFetches)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, w := range next {
+			if visited[w.key()] {
+				continue
+			}
+			visited[w.key()] = true
+			if w.node.Title == to && w.node.Lang == toLang {
+				return w.path(from, to), nil
+			}
+			queue = append(queue, w)
+		}
+	}
+
+	return nil, notFound
+}
+
+func (v *vertex) path(from, to string) *Path {
+	p := &Path{From: from, To: to}
+	for ; v != nil; v = v.prev {
+		p.Nodes = append([]*Node{v.node}, p.Nodes...)
+		if v.edge != nil {
+			p.Edges = append([]*Edge{v.edge}, p.Edges...)
+		}
+	}
+	if p.Edges == nil {
+		p.Edges = []*Edge{}
+	}
+
+	return p
+}
+
+// neighbours lists the senses one link away from v.
+func (b *builder) neighbours(v *vertex, langs ...string) ([]*vertex, error) {
+	t, lang, m := v.node.ID, v.node.Lang, v.meaning
+	var next []*vertex
+
+	link := func(h, l string, meanings parser.Meanings, idx int, rel Relation, kind Kind, tooltip string, polysemous bool, score float64) {
+		n := newNode(h, l, idx, meanings)
+		n.Depth = v.length + 1
+		next = append(next, &vertex{
+			node:    n,
+			meaning: meanings[idx],
+			prev:    v,
+			length:  v.length + 1,
+			edge: &Edge{
+				From:     t,
+				To:       n.ID,
+				Relation: rel,
+				Kind:     kind,
+				Provenance: Provenance{
+					Tooltip:    tooltip,
+					Strict:     b.opts.Strict,
+					Polysemous: polysemous,
+					Confidence: score,
+				},
+			},
+		})
+	}
+
+	own := []hop{
+		{Hyperonymy, m.Hyperonyms, "%s->%s", hyponyms},
+		{Hyponymy, m.Hyponyms, "%s<-%s", hyperonyms},
+	}
+	if b.opts.Relations[Synonymy] > 0 {
+		own = append(own, hop{Synonymy, m.Synonyms, "%s-synonymy->%s", synonyms})
+	}
+
+	for _, o := range own {
+		for _, h := range o.titles {
+			meanings, err := b.meanings(h, lang)
+			if err != nil {
+				return nil, err
+			}
+			if meanings == nil {
+				continue
+			}
+
+			tooltip := fmt.Sprintf(o.format, t, h)
+			c := &Candidate{
+				Key:      tooltip,
+				Title:    h,
+				Lang:     lang,
+				Meanings: meanings,
+				From:     m,
+				Lemmas:   []string{v.node.Title},
+				Back:     o.back,
+			}
+			idx, err := b.choose(c)
+			if err != nil {
+				return nil, err
+			}
+			if idx == -1 {
+				continue
+			}
+			if len(meanings) > 1 {
+				tooltip += ":" + strconv.Itoa(idx)
+			}
+			link(h, lang, meanings, idx, o.rel, Own, tooltip, len(meanings) > 1, confidence(c, idx))
+		}
+	}
+
+	if lang != wikt.Russian {
+		hs, pps, err := b.predict(v.node.Title, lang, m, m.Hyperonyms)
+		if err != nil {
+			return nil, err
+		}
+		for i, h := range hs {
+			meanings, err := b.meanings(h, lang)
+			if err != nil {
+				return nil, err
+			}
+			for idx, mh := range meanings {
+				if contains(mh.Translations.ByLanguage(pps[i].pivot), pps[i].h) {
+					link(h, lang, meanings, idx, Hyperonymy, Predicted, pps[i].tooltip, pps[i].polysemous, pps[i].confidence)
+					next[len(next)-1].edge.Pivots = pps[i].pivots
+					break
+				}
+			}
+		}
+	}
+
+	if b.opts.Relations[Translation] > 0 {
+		seen := map[string]bool{lang: true}
+		for _, l := range append(langs, wikt.Russian) {
+			if seen[l] {
+				continue
+			}
+			seen[l] = true
+			for _, h := range m.Translations.ByLanguage(l) {
+				meanings, err := b.meanings(h, l)
+				if err != nil {
+					return nil, err
+				}
+				if meanings == nil {
+					continue
+				}
+
+				tooltip := fmt.Sprintf("%s-%s->%s", t, Translation, h)
+				c := &Candidate{
+					Key:      tooltip,
+					Title:    h,
+					Lang:     l,
+					Meanings: meanings,
+					From:     m,
+					Lemmas:   []string{v.node.Title},
+					Back: func(mh *parser.Meaning) []string {
+						return mh.Translations.ByLanguage(lang)
+					},
+				}
+				idx, err := b.choose(c)
+				if err != nil {
+					return nil, err
+				}
+				if idx == -1 {
+					continue
+				}
+				if len(meanings) > 1 {
+					tooltip += ":" + strconv.Itoa(idx)
+				}
+
+				link(h, l, meanings, idx, Translation, Own, tooltip, len(meanings) > 1, confidence(c, idx))
+			}
+		}
+	}
+
+	return next, nil
+}
+
+// meanings fetches the senses of title in lang, nil for missing words.
+func (b *builder) meanings(title, lang string) (parser.Meanings, error) {
+	word, err := b.fetch(title)
+	if err != nil {
+		if err == wikt.ErrMissing {
+			b.g.trace(title, title, Missing, "%v", err)
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return word.ByLanguage(lang), nil
+}
+
+func newNode(title, lang string, idx int, meanings parser.Meanings) *Node {
+	name := title
+	if len(meanings) > 1 {
+		name += fmt.Sprintf(":%d", idx)
+	}
+
+	return &Node{
+		ID:         name,
+		Title:      title,
+		Lang:       lang,
+		Sense:      idx,
+		Meaning:    meanings[idx].Value,
+		Polysemous: len(meanings) > 1,
+	}
+}
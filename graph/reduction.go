@@ -0,0 +1,74 @@
+package graph
+
+import "strings"
+
+// Reduce performs the transitive reduction of the hyperonymy edges:
+// an edge implied by a longer path is moved to Redundant. Cycles are
+// collapsed first, so that edges entering one never imply each other,
+// and edges lying on cycles are kept.
+func (t *Taxonomy) Reduce() {
+	adj := make(map[string][]string)
+	for _, e := range t.Edges {
+		if e.Relation == Hyperonymy {
+			adj[e.From] = append(adj[e.From], e.To)
+		}
+	}
+
+	// The condensation links the components through their first nodes.
+	first := make(map[string]string)
+	for _, scc := range components(t.Nodes, adj) {
+		for _, id := range scc {
+			first[id] = scc[0]
+		}
+	}
+	dag := make(map[string][]string)
+	for from, tos := range adj {
+		for _, to := range tos {
+			if first[from] != first[to] {
+				dag[first[from]] = append(dag[first[from]], first[to])
+			}
+		}
+	}
+
+	var redundant []*Edge
+	for _, e := range t.Edges {
+		if e.Relation == Hyperonymy && first[e.From] != first[e.To] && reachable(dag, first[e.From], first[e.To]) {
+			redundant = append(redundant, e)
+		}
+	}
+
+	for _, e := range redundant {
+		t.RemoveEdge(e.From, e.To, e.Relation)
+		t.Redundant = append(t.Redundant, e)
+		t.trace(e.Tooltip, strings.Split(e.To, ":")[0], Dropped, "implied by a longer path")
+	}
+}
+
+// reachable tells whether to can be reached from from
+// by a path of at least two edges.
+func reachable(adj map[string][]string, from, to string) bool {
+	visited := map[string]bool{from: true}
+	var queue []string
+	for _, v := range adj[from] {
+		if v != to && !visited[v] {
+			visited[v] = true
+			queue = append(queue, v)
+		}
+	}
+
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, w := range adj[v] {
+			if w == to {
+				return true
+			}
+			if !visited[w] {
+				visited[w] = true
+				queue = append(queue, w)
+			}
+		}
+	}
+
+	return false
+}
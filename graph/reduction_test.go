@@ -0,0 +1,71 @@
+package graph
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReduce(t *testing.T) {
+	tests := []struct {
+		name      string
+		edges     []string
+		redundant []string
+	}{
+		{
+			name:      "shortcut",
+			edges:     []string{"кот->кошка", "кошка->животное", "кот->животное"},
+			redundant: []string{"кот->животное"},
+		},
+		{
+			name:      "long shortcut",
+			edges:     []string{"a->b", "b->c", "c->d", "a->d"},
+			redundant: []string{"a->d"},
+		},
+		{
+			name:      "diamond",
+			edges:     []string{"a->b", "a->c", "b->d", "c->d"},
+			redundant: []string{},
+		},
+		{
+			name:      "edges entering a cycle",
+			edges:     []string{"a->b", "a->c", "b->c", "c->b"},
+			redundant: []string{},
+		},
+		{
+			name:      "shortcut over a cycle",
+			edges:     []string{"a->b", "b->c", "c->b", "c->d", "a->d"},
+			redundant: []string{"a->d"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := taxonomyOf(tt.edges...)
+			g.Reduce()
+			if got := chains(g.Redundant); !reflect.DeepEqual(got, tt.redundant) {
+				t.Errorf("Redundant = %v, want %v", got, tt.redundant)
+			}
+			for _, e := range g.Redundant {
+				if g.Edge(e.From, e.To, e.Relation) != nil {
+					t.Errorf("%s->%s is still in the taxonomy", e.From, e.To)
+				}
+			}
+			if len(g.Edges)+len(g.Redundant) != len(tt.edges) {
+				t.Errorf("%d edges kept, %d redundant out of %d", len(g.Edges), len(g.Redundant), len(tt.edges))
+			}
+		})
+	}
+}
+
+func TestDOTRedundantEdges(t *testing.T) {
+	g := taxonomyOf("a->b", "b->c", "a->c")
+	g.Reduce()
+
+	if strings.Contains(g.DOT(false), "redundant") {
+		t.Errorf("DOT(false) draws redundant edges:\n%s", g.DOT(false))
+	}
+	if !strings.Contains(g.DOT(true), `id="redundant0"`) {
+		t.Errorf("DOT(true) misses redundant edges:\n%s", g.DOT(true))
+	}
+}
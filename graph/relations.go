@@ -0,0 +1,137 @@
+package graph
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/stillpiercer/wikitologies/parser"
+	wikt "github.com/stillpiercer/wikitologies/wiktionary"
+)
+
+// SideRelations are the relations Build can draw besides hyperonymy.
+var SideRelations = []Relation{Synonymy, Antonymy, Meronymy, Holonymy}
+
+func related(m *parser.Meaning, r Relation) []string {
+	switch r {
+	case Synonymy:
+		return m.Synonyms
+	case Antonymy:
+		return m.Antonyms
+	case Meronymy:
+		return m.Meronyms
+	case Holonymy:
+		return m.Holonyms
+	}
+
+	return nil
+}
+
+// inverse is the relation a target sense lists its source with.
+func inverse(r Relation) Relation {
+	switch r {
+	case Meronymy:
+		return Holonymy
+	case Holonymy:
+		return Meronymy
+	}
+
+	return r
+}
+
+func symmetric(r Relation) bool {
+	return r == Synonymy || r == Antonymy
+}
+
+// relate schedules the side relations of the node name. Nodes of the
+// hyperonym skeleton start every enabled relation, nodes reached through
+// a side relation only continue it until its limit in Options.Relations.
+func (b *builder) relate(name string, meaning *parser.Meaning, depth int, via Relation, hops int) {
+	for _, r := range SideRelations {
+		limit := b.opts.Relations[r]
+		if limit <= 0 || via != "" && r != via {
+			continue
+		}
+
+		hs := related(meaning, r)
+		if len(hs) == 0 {
+			continue
+		}
+		if hops >= limit {
+			b.g.trace(name, name, Limited, "%s limit of %d reached", r, limit)
+			b.truncate(name)
+			continue
+		}
+
+		b.stack.pushRelation(name, depth, r, hops, hs)
+		log.Printf("%s %s: %s", name, r, hs)
+	}
+}
+
+// stepRelation checks the side relation p.rel between p.t and p.h: in strict
+// mode the sense of p.h has to list p.t back through the inverse relation.
+func (b *builder) stepRelation(p pair) error {
+	t, h := p.t, p.h
+	log.Printf("%s -> %s [%s]: checking...", t, h, p.rel)
+
+	word, err := b.fetch(h)
+	if err != nil {
+		if err == wikt.ErrMissing {
+			b.g.trace(fmt.Sprintf("%s-%s->%s", t, p.rel, h), h, Missing, "%v", err)
+			return nil
+		}
+		if err == errBudget {
+			b.g.trace(fmt.Sprintf("%s-%s->%s", t, p.rel, h), h, Limited, "%v", err)
+			b.truncate(t)
+			return nil
+		}
+		return err
+	}
+
+	meanings := word.ByLanguage(b.lang)
+	l := len(meanings)
+	if l == 0 {
+		return nil
+	}
+
+	tooltip := fmt.Sprintf("%s-%s->%s", t, p.rel, h)
+	c := b.candidate(t, h, tooltip, meanings, func(m *parser.Meaning) []string {
+		return related(m, inverse(p.rel))
+	})
+	idx, err := b.choose(c)
+	if err != nil {
+		return err
+	}
+	if idx == -1 {
+		return nil
+	}
+
+	name := h
+	if l > 1 {
+		name += fmt.Sprintf(":%d", idx)
+		tooltip += ":" + strconv.Itoa(idx)
+	}
+	if symmetric(p.rel) && b.g.Edge(name, t, p.rel) != nil {
+		return nil
+	}
+
+	return b.attach(p, &Node{
+		ID:         name,
+		Title:      h,
+		Lang:       b.lang,
+		Sense:      idx,
+		Meaning:    meanings[idx].Value,
+		Polysemous: l > 1,
+	}, &Edge{
+		From:     t,
+		To:       name,
+		Relation: p.rel,
+		Kind:     Own,
+		Provenance: Provenance{
+			Tooltip:    tooltip,
+			Strict:     b.opts.Strict,
+			Polysemous: l > 1,
+			Confidence: confidence(c, idx),
+		},
+	}, meanings[idx])
+}
@@ -0,0 +1,227 @@
+package graph
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+const (
+	savedKey    = "saved"
+	savedPrefix = "saved:"
+)
+
+var (
+	ErrNoTaxonomy = errors.New("taxonomy not found")
+	ErrNoRevision = errors.New("revision not found")
+)
+
+// Revision is a version of a saved taxonomy: the words it is built from
+// and the query params tuning the build, presets included.
+type Revision struct {
+	Number  int               `json:"number"`
+	Titles  []string          `json:"titles"`
+	Lang    string            `json:"lang"`
+	Params  map[string]string `json:"params"`
+	Change  string            `json:"change"`
+	Created time.Time         `json:"created"`
+}
+
+// Saved is a named taxonomy with its revisions, the last one is current.
+type Saved struct {
+	Name      string      `json:"name"`
+	Revisions []*Revision `json:"revisions"`
+}
+
+func (s *Saved) Revision(n int) (*Revision, error) {
+	if n < 1 || n > len(s.Revisions) {
+		return nil, ErrNoRevision
+	}
+
+	return s.Revisions[n-1], nil
+}
+
+func (s *Saved) Current() *Revision {
+	return s.Revisions[len(s.Revisions)-1]
+}
+
+// SavedSummary describes a saved taxonomy in listings.
+type SavedSummary struct {
+	Name      string    `json:"name"`
+	Revisions int       `json:"revisions"`
+	Updated   time.Time `json:"updated"`
+}
+
+// ParamChange is a difference between two revisions, Key is titles, lang
+// or a param. Old or New is empty for params added or removed.
+type ParamChange struct {
+	Key string `json:"key"`
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// Save records rev as the next revision of the taxonomy name, creating it
+// if needed. A revision equal to the current one is not recorded again.
+func Save(name string, rev *Revision, pool *redis.Pool) (*Revision, error) {
+	if name == "" || strings.ContainsAny(name, "/") {
+		return nil, fmt.Errorf("invalid taxonomy name %q", name)
+	}
+
+	c := pool.Get()
+	defer c.Close()
+
+	s, err := getSaved(c, name)
+	if err != nil && err != ErrNoTaxonomy {
+		return nil, err
+	}
+	if s != nil {
+		current := s.Current()
+		changes := DiffRevisions(current, rev)
+		if len(changes) == 0 {
+			return current, nil
+		}
+		if rev.Change == "" {
+			rev.Change = describe(changes)
+		}
+	} else if rev.Change == "" {
+		rev.Change = "created"
+	}
+
+	rev.Created = time.Now().UTC()
+	data, err := json.Marshal(rev)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := redis.Int(c.Do("RPUSH", savedPrefix+name, data))
+	if err != nil {
+		return nil, err
+	}
+	_, err = c.Do("SADD", savedKey, name)
+	if err != nil {
+		return nil, err
+	}
+
+	rev.Number = n
+	return rev, nil
+}
+
+// Restore records a copy of the revision n of the taxonomy name as the current one.
+func Restore(name string, n int, pool *redis.Pool) (*Revision, error) {
+	s, err := GetSaved(name, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	old, err := s.Revision(n)
+	if err != nil {
+		return nil, err
+	}
+
+	return Save(name, &Revision{
+		Titles: old.Titles,
+		Lang:   old.Lang,
+		Params: old.Params,
+		Change: fmt.Sprintf("restored revision %d", n),
+	}, pool)
+}
+
+func GetSaved(name string, pool *redis.Pool) (*Saved, error) {
+	c := pool.Get()
+	defer c.Close()
+
+	return getSaved(c, name)
+}
+
+func ListSaved(pool *redis.Pool) ([]SavedSummary, error) {
+	c := pool.Get()
+	defer c.Close()
+
+	names, err := redis.Strings(c.Do("SMEMBERS", savedKey))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	summaries := make([]SavedSummary, 0, len(names))
+	for _, name := range names {
+		s, err := getSaved(c, name)
+		if err == ErrNoTaxonomy {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		summaries = append(summaries, SavedSummary{
+			Name:      name,
+			Revisions: len(s.Revisions),
+			Updated:   s.Current().Created,
+		})
+	}
+
+	return summaries, nil
+}
+
+// DiffRevisions lists the titles, lang and params changed from a to b, sorted by key.
+func DiffRevisions(a, b *Revision) []ParamChange {
+	changes := []ParamChange{}
+	if before, after := strings.Join(a.Titles, "+"), strings.Join(b.Titles, "+"); before != after {
+		changes = append(changes, ParamChange{Key: "titles", Old: before, New: after})
+	}
+	if a.Lang != b.Lang {
+		changes = append(changes, ParamChange{Key: "lang", Old: a.Lang, New: b.Lang})
+	}
+	for k, v := range a.Params {
+		if b.Params[k] != v {
+			changes = append(changes, ParamChange{Key: k, Old: v, New: b.Params[k]})
+		}
+	}
+	for k, v := range b.Params {
+		if _, ok := a.Params[k]; !ok {
+			changes = append(changes, ParamChange{Key: k, New: v})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+
+	return changes
+}
+
+func describe(changes []ParamChange) string {
+	var parts []string
+	for _, c := range changes {
+		parts = append(parts, fmt.Sprintf("%s: %s -> %s", c.Key, c.Old, c.New))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func getSaved(c redis.Conn, name string) (*Saved, error) {
+	values, err := redis.ByteSlices(c.Do("LRANGE", savedPrefix+name, 0, -1))
+	if err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return nil, ErrNoTaxonomy
+	}
+
+	s := &Saved{Name: name}
+	for i, data := range values {
+		rev := &Revision{}
+		err = json.Unmarshal(data, rev)
+		if err != nil {
+			return nil, fmt.Errorf("taxonomy %s: %v", name, err)
+		}
+		rev.Number = i + 1
+		s.Revisions = append(s.Revisions, rev)
+	}
+
+	return s, nil
+}
@@ -0,0 +1,215 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/stillpiercer/wikitologies/parser"
+)
+
+// Candidate is a word whose sense has to be selected.
+type Candidate struct {
+	// Key identifies the choice in Options.Presets: the title of a seed
+	// word or the chain it is reached through, e.g. кот->животное.
+	Key      string
+	Title    string
+	Lang     string
+	Meanings parser.Meanings
+	// From is the sense the candidate is reached from and Lemmas are
+	// its words, both are empty for seed words.
+	From   *parser.Meaning
+	Lemmas []string
+	// Back lists the words a sense of the candidate links back with.
+	Back func(*parser.Meaning) []string
+}
+
+// SenseSelector picks the sense of a candidate or abstains with ok set to false.
+type SenseSelector interface {
+	Select(c *Candidate) (idx int, reason string, ok bool)
+}
+
+// Selectors lists the selectors available by name, see ParseSelectors.
+var Selectors = map[string]func(presets map[string]int) SenseSelector{
+	"presets":      func(presets map[string]int) SenseSelector { return PresetSelector(presets) },
+	"overrides":    func(map[string]int) SenseSelector { return OverrideSelector{} },
+	"verbal":       func(map[string]int) SenseSelector { return VerbalNounSelector{} },
+	"first":        func(map[string]int) SenseSelector { return FirstSenseSelector{} },
+	"backlink":     func(map[string]int) SenseSelector { return BackLinkSelector{} },
+	"lesk":         func(map[string]int) SenseSelector { return LeskSelector{} },
+	"translations": func(map[string]int) SenseSelector { return TranslationSelector{} },
+}
+
+// selectorName finds the name s is registered with in Selectors.
+func selectorName(s SenseSelector) string {
+	for name, selector := range Selectors {
+		if fmt.Sprintf("%T", selector(nil)) == fmt.Sprintf("%T", s) {
+			return name
+		}
+	}
+
+	return fmt.Sprintf("%T", s)
+}
+
+// ParseSelectors builds a chain of selectors from their names.
+func ParseSelectors(names []string, presets map[string]int) ([]SenseSelector, error) {
+	var chain []SenseSelector
+	for _, name := range names {
+		selector, ok := Selectors[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown sense selector %s", name)
+		}
+		chain = append(chain, selector(presets))
+	}
+
+	return chain, nil
+}
+
+// naiveSelectors is the default chain of the naive mode and of seed words.
+func naiveSelectors(presets map[string]int) []SenseSelector {
+	return []SenseSelector{
+		PresetSelector(presets),
+		OverrideSelector{},
+		VerbalNounSelector{},
+		FirstSenseSelector{},
+	}
+}
+
+// strictSelectors is the default chain of the strict mode.
+func strictSelectors() []SenseSelector {
+	return []SenseSelector{BackLinkSelector{}}
+}
+
+// PresetSelector picks the senses chosen by users through /edit.
+type PresetSelector map[string]int
+
+func (s PresetSelector) Select(c *Candidate) (int, string, bool) {
+	i, ok := s[c.Key]
+	return i, "preset " + c.Key, ok
+}
+
+// OverrideSelector picks the senses fixed globally for recurring words, see LoadOverrides.
+// Overrides of senses the word no longer has are skipped.
+type OverrideSelector struct{}
+
+func (OverrideSelector) Select(c *Candidate) (int, string, bool) {
+	o, ok := override(c.Title, c.Lang)
+	if !ok || o.Sense >= len(c.Meanings) {
+		return 0, "", false
+	}
+
+	return o.Sense, fmt.Sprintf("global override by %s: %s", o.Author, o.Reason), true
+}
+
+// VerbalNounSelector skips a first sense of the form "действие по значению гл. ...".
+type VerbalNounSelector struct{}
+
+func (VerbalNounSelector) Select(c *Candidate) (int, string, bool) {
+	if len(c.Meanings) > 1 && strings.HasPrefix(c.Meanings[0].Value, "действие по значению гл.") {
+		return 1, "first sense is a verbal noun", true
+	}
+
+	return 0, "", false
+}
+
+type FirstSenseSelector struct{}
+
+func (FirstSenseSelector) Select(*Candidate) (int, string, bool) {
+	return 0, "first sense", true
+}
+
+// BackLinkSelector picks the first sense linking back to the word it is reached from.
+type BackLinkSelector struct{}
+
+func (BackLinkSelector) Select(c *Candidate) (int, string, bool) {
+	if c.Back == nil {
+		return 0, "", false
+	}
+
+	for i, m := range c.Meanings {
+		if containsAny(c.Back(m), c.Lemmas) {
+			return i, fmt.Sprintf("sense links back to %s", strings.Join(c.Lemmas, ", ")), true
+		}
+	}
+
+	return 0, "", false
+}
+
+// LeskSelector picks the sense whose definition shares
+// the most words with the definition it is reached from.
+type LeskSelector struct{}
+
+func (LeskSelector) Select(c *Candidate) (int, string, bool) {
+	if c.From == nil {
+		return 0, "", false
+	}
+
+	gloss := words(c.From.Value)
+	for _, e := range c.From.Examples {
+		for w := range words(e) {
+			gloss[w] = true
+		}
+	}
+
+	best, score := -1, 0
+	for i, m := range c.Meanings {
+		var n int
+		for w := range words(m.Value) {
+			if gloss[w] {
+				n++
+			}
+		}
+		if n > score {
+			best, score = i, n
+		}
+	}
+	if best == -1 {
+		return 0, "", false
+	}
+
+	return best, fmt.Sprintf("%d words shared with the definition", score), true
+}
+
+// TranslationSelector picks the sense sharing the most translations
+// with the sense it is reached from.
+type TranslationSelector struct{}
+
+func (TranslationSelector) Select(c *Candidate) (int, string, bool) {
+	if c.From == nil {
+		return 0, "", false
+	}
+
+	best, score := -1, 0
+	for i, m := range c.Meanings {
+		var n int
+		for _, t := range m.Translations {
+			for _, v := range t.Values {
+				if contains(c.From.Translations.ByLanguage(t.Language), v) {
+					n++
+				}
+			}
+		}
+		if n > score {
+			best, score = i, n
+		}
+	}
+	if best == -1 {
+		return 0, "", false
+	}
+
+	return best, fmt.Sprintf("%d translations shared", score), true
+}
+
+// words splits text into lower-cased words long enough to carry meaning.
+func words(text string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}) {
+		if len([]rune(w)) > 3 {
+			set[w] = true
+		}
+	}
+
+	return set
+}
@@ -0,0 +1,184 @@
+package graph
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// Similarity describes how close the given words are in their hyperonym taxonomy.
+type Similarity struct {
+	Lang  string   `json:"lang"`
+	Words []string `json:"words"`
+	// Common lists the lowest common hyperonyms of all the words.
+	Common []string          `json:"common"`
+	Pairs  []*PairSimilarity `json:"pairs"`
+}
+
+// PairSimilarity holds path-based scores of two words. Words without a common
+// hyperonym meet at a virtual root above the taxonomy.
+type PairSimilarity struct {
+	A               string   `json:"a"`
+	B               string   `json:"b"`
+	Common          []string `json:"common"`
+	PathLength      int      `json:"path_length"`
+	WuPalmer        float64  `json:"wu_palmer"`
+	LeacockChodorow float64  `json:"leacock_chodorow"`
+}
+
+// Similar builds the upward taxonomy of words and compares them in it.
+// Cycles are broken and depths are measured within that taxonomy,
+// so scores are relative to it.
+func Similar(words []string, lang string, opts Options, pool *redis.Pool) (*Similarity, error) {
+	if len(words) < 2 {
+		return nil, fmt.Errorf("для сравнения нужно хотя бы 2 слова, передано %d", len(words))
+	}
+
+	opts.Direction = Up
+	opts.Relations = nil
+	opts.BreakCycles = true
+	opts.Reduce = false
+	t, err := Build(words, lang, opts, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(words))
+	for i, w := range words {
+		for _, n := range t.Nodes {
+			if n.Root && (n.Title == w || contains(n.Lemmas, w)) {
+				ids[i] = n.ID
+				break
+			}
+		}
+		if ids[i] == "" {
+			return nil, fmt.Errorf("слово %s (%s) не найдено", w, lang)
+		}
+	}
+
+	h := newHierarchy(t)
+	s := &Similarity{
+		Lang:   lang,
+		Words:  words,
+		Common: h.lowestCommon(ids...),
+		Pairs:  []*PairSimilarity{},
+	}
+
+	for i := range ids {
+		for j := i + 1; j < len(ids); j++ {
+			s.Pairs = append(s.Pairs, h.compare(ids[i], ids[j]))
+		}
+	}
+
+	return s, nil
+}
+
+// hierarchy is the hyperonymy part of a taxonomy with node depths,
+// top nodes having depth 1 below the virtual root.
+type hierarchy struct {
+	up       map[string][]string
+	depth    map[string]int
+	maxDepth int
+}
+
+func newHierarchy(t *Taxonomy) *hierarchy {
+	h := &hierarchy{
+		up:    make(map[string][]string),
+		depth: make(map[string]int),
+	}
+	for _, e := range t.Edges {
+		if e.Relation == Hyperonymy {
+			h.up[e.From] = append(h.up[e.From], e.To)
+		}
+	}
+
+	for _, n := range t.Nodes {
+		d := math.MaxInt32
+		for id, dist := range h.ancestors(n.ID) {
+			if len(h.up[id]) == 0 && dist+1 < d {
+				d = dist + 1
+			}
+		}
+		h.depth[n.ID] = d
+		if d > h.maxDepth {
+			h.maxDepth = d
+		}
+	}
+
+	return h
+}
+
+// ancestors returns the distances from id to every node above it, id included.
+func (h *hierarchy) ancestors(id string) map[string]int {
+	dist := map[string]int{id: 0}
+	queue := []string{id}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, w := range h.up[v] {
+			if _, ok := dist[w]; !ok {
+				dist[w] = dist[v] + 1
+				queue = append(queue, w)
+			}
+		}
+	}
+
+	return dist
+}
+
+// lowestCommon returns the common ancestors of ids with the least total distance.
+func (h *hierarchy) lowestCommon(ids ...string) []string {
+	total := h.ancestors(ids[0])
+	for _, id := range ids[1:] {
+		dist := h.ancestors(id)
+		for a := range total {
+			if d, ok := dist[a]; ok {
+				total[a] += d
+			} else {
+				delete(total, a)
+			}
+		}
+	}
+
+	best := math.MaxInt32
+	for _, d := range total {
+		if d < best {
+			best = d
+		}
+	}
+
+	common := []string{}
+	for a, d := range total {
+		if d == best {
+			common = append(common, a)
+		}
+	}
+	sort.Strings(common)
+
+	return common
+}
+
+func (h *hierarchy) compare(a, b string) *PairSimilarity {
+	p := &PairSimilarity{A: a, B: b, Common: h.lowestCommon(a, b)}
+	distA, distB := h.ancestors(a), h.ancestors(b)
+
+	var depth int
+	if len(p.Common) == 0 {
+		p.PathLength = h.depth[a] + h.depth[b]
+	} else {
+		p.PathLength = math.MaxInt32
+		for _, c := range p.Common {
+			if l := distA[c] + distB[c]; l < p.PathLength || l == p.PathLength && h.depth[c] > depth {
+				p.PathLength = l
+				depth = h.depth[c]
+			}
+		}
+	}
+
+	p.WuPalmer = 2 * float64(depth) / float64(p.PathLength+2*depth)
+	p.LeacockChodorow = -math.Log(float64(p.PathLength+1) / float64(2*h.maxDepth))
+
+	return p
+}
@@ -0,0 +1,121 @@
+package graph
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// animals is a small hyperonym taxonomy with a separate tree of tools.
+func animals() *hierarchy {
+	return newHierarchy(taxonomyOf(
+		"кот->кошка", "кошка->животное", "пёс->собака", "собака->животное",
+		"животное->организм", "молоток->инструмент",
+	))
+}
+
+func TestHierarchyDepth(t *testing.T) {
+	h := animals()
+
+	want := map[string]int{
+		"организм":   1,
+		"животное":   2,
+		"кошка":      3,
+		"собака":     3,
+		"кот":        4,
+		"пёс":        4,
+		"инструмент": 1,
+		"молоток":    2,
+	}
+	if !reflect.DeepEqual(h.depth, want) {
+		t.Errorf("depth = %v, want %v", h.depth, want)
+	}
+	if h.maxDepth != 4 {
+		t.Errorf("maxDepth = %d, want 4", h.maxDepth)
+	}
+}
+
+func TestHierarchyDepthTakesShortestPath(t *testing.T) {
+	h := newHierarchy(taxonomyOf("кот->кошка", "кошка->животное", "кот->животное"))
+
+	if h.depth["кот"] != 2 {
+		t.Errorf("depth of кот = %d, want 2", h.depth["кот"])
+	}
+}
+
+func TestLowestCommon(t *testing.T) {
+	tests := []struct {
+		name string
+		ids  []string
+		want []string
+	}{
+		{"siblings", []string{"кот", "пёс"}, []string{"животное"}},
+		{"ancestor", []string{"кот", "кошка"}, []string{"кошка"}},
+		{"three words", []string{"кот", "пёс", "кошка"}, []string{"животное"}},
+		{"same word", []string{"кот", "кот"}, []string{"кот"}},
+		{"separate trees", []string{"кот", "молоток"}, []string{}},
+	}
+
+	h := animals()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := h.lowestCommon(tt.ids...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lowestCommon(%v) = %v, want %v", tt.ids, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLowestCommonTies(t *testing.T) {
+	h := newHierarchy(taxonomyOf("a->p", "a->q", "b->p", "b->q"))
+
+	if got, want := h.lowestCommon("a", "b"), []string{"p", "q"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("lowestCommon = %v, want %v", got, want)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b            string
+		common          []string
+		pathLength      int
+		wuPalmer        float64
+		leacockChodorow float64
+	}{
+		// Meet at животное of depth 2: 2*2 / (4 + 2*2).
+		{"кот", "пёс", []string{"животное"}, 4, 0.5, -math.Log(5.0 / 8)},
+		// кошка of depth 3 is the hyperonym of кот: 2*3 / (1 + 2*3).
+		{"кот", "кошка", []string{"кошка"}, 1, 6.0 / 7, -math.Log(2.0 / 8)},
+		{"кот", "кот", []string{"кот"}, 0, 1, -math.Log(1.0 / 8)},
+		// No common hyperonym: the path goes through the virtual root.
+		{"кот", "молоток", []string{}, 6, 0, -math.Log(7.0 / 8)},
+	}
+
+	h := animals()
+	for _, tt := range tests {
+		t.Run(tt.a+"-"+tt.b, func(t *testing.T) {
+			p := h.compare(tt.a, tt.b)
+			if !reflect.DeepEqual(p.Common, tt.common) {
+				t.Errorf("Common = %v, want %v", p.Common, tt.common)
+			}
+			if p.PathLength != tt.pathLength {
+				t.Errorf("PathLength = %d, want %d", p.PathLength, tt.pathLength)
+			}
+			if math.Abs(p.WuPalmer-tt.wuPalmer) > 1e-9 {
+				t.Errorf("WuPalmer = %v, want %v", p.WuPalmer, tt.wuPalmer)
+			}
+			if math.Abs(p.LeacockChodorow-tt.leacockChodorow) > 1e-9 {
+				t.Errorf("LeacockChodorow = %v, want %v", p.LeacockChodorow, tt.leacockChodorow)
+			}
+		})
+	}
+}
+
+func TestCompareIsSymmetric(t *testing.T) {
+	h := animals()
+
+	ab, ba := h.compare("кот", "собака"), h.compare("собака", "кот")
+	if ab.PathLength != ba.PathLength || ab.WuPalmer != ba.WuPalmer || ab.LeacockChodorow != ba.LeacockChodorow {
+		t.Errorf("compare(кот, собака) = %+v, compare(собака, кот) = %+v", ab, ba)
+	}
+}
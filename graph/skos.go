@@ -0,0 +1,249 @@
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/stillpiercer/wikitologies/parser"
+	wikt "github.com/stillpiercer/wikitologies/wiktionary"
+)
+
+const skosNamespace = "http://www.w3.org/2004/02/skos/core#"
+
+// IRIs configure the identifiers of SKOS exports.
+type IRIs struct {
+	// Concepts prefixes the IRIs of concepts, which end with node IDs,
+	// e.g. https://example.org/concepts/ gives https://example.org/concepts/кот:0.
+	Concepts string
+	// Scheme is the IRI of the concept scheme the concepts are in.
+	Scheme string
+}
+
+// Validate checks that Concepts and Scheme are absolute IRIs,
+// as exports write them out unescaped.
+func (iris IRIs) Validate() error {
+	for _, iri := range []string{iris.Concepts, iris.Scheme} {
+		u, err := url.Parse(iri)
+		if err != nil {
+			return fmt.Errorf("invalid IRI %q: %v", iri, err)
+		}
+		if !u.IsAbs() || u.Host == "" && u.Opaque == "" {
+			return fmt.Errorf("IRI %q is not absolute", iri)
+		}
+		if strings.ContainsAny(iri, " <>\"{}|^`\\") {
+			return fmt.Errorf("IRI %q has characters not allowed in IRIs", iri)
+		}
+	}
+
+	return nil
+}
+
+// concept is a sense of a word as a skos:Concept.
+type concept struct {
+	IRI        string
+	Lang       string
+	PrefLabel  string
+	AltLabels  []string
+	Definition string
+	Broader    []string
+}
+
+// concepts maps nodes to concepts: hyperonymy edges make up skos:broader,
+// synonyms of senses, linked by edges or merged into synsets skos:altLabel.
+func (t *Taxonomy) concepts(iris IRIs) []*concept {
+	iri := func(id string) string {
+		return iris.Concepts + url.PathEscape(id)
+	}
+
+	concepts := make([]*concept, 0, len(t.Nodes))
+	byID := make(map[string]*concept, len(t.Nodes))
+	for _, n := range t.Nodes {
+		c := &concept{
+			IRI:        iri(n.ID),
+			Lang:       langTag(n.Lang),
+			PrefLabel:  n.Title,
+			Definition: n.Meaning,
+		}
+		for _, labels := range [][]string{n.Lemmas, n.Synonyms} {
+			for _, l := range labels {
+				if l != n.Title && !contains(c.AltLabels, l) {
+					c.AltLabels = append(c.AltLabels, l)
+				}
+			}
+		}
+		concepts = append(concepts, c)
+		byID[n.ID] = c
+	}
+
+	for _, e := range t.Edges {
+		from, to := byID[e.From], byID[e.To]
+		if from == nil || to == nil {
+			continue
+		}
+
+		switch e.Relation {
+		case Hyperonymy:
+			from.Broader = append(from.Broader, to.IRI)
+		case Synonymy:
+			if !contains(from.AltLabels, to.PrefLabel) && to.PrefLabel != from.PrefLabel {
+				from.AltLabels = append(from.AltLabels, to.PrefLabel)
+			}
+			if !contains(to.AltLabels, from.PrefLabel) && from.PrefLabel != to.PrefLabel {
+				to.AltLabels = append(to.AltLabels, from.PrefLabel)
+			}
+		}
+	}
+
+	return concepts
+}
+
+// Turtle renders the taxonomy as a SKOS concept scheme in Turtle.
+func (t *Taxonomy) Turtle(iris IRIs) ([]byte, error) {
+	concepts := t.concepts(iris)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "@prefix skos: <%s> .\n\n", skosNamespace)
+
+	fmt.Fprintf(&b, "<%s> a skos:ConceptScheme ;\n", iris.Scheme)
+	fmt.Fprintf(&b, "    skos:prefLabel %s", turtleLiteral(t.Name, ""))
+	var top []string
+	for _, c := range concepts {
+		if len(c.Broader) == 0 {
+			top = append(top, "<"+c.IRI+">")
+		}
+	}
+	if len(top) > 0 {
+		fmt.Fprintf(&b, " ;\n    skos:hasTopConcept %s", strings.Join(top, ", "))
+	}
+	b.WriteString(" .\n")
+
+	for _, c := range concepts {
+		fmt.Fprintf(&b, "\n<%s> a skos:Concept ;\n", c.IRI)
+		fmt.Fprintf(&b, "    skos:inScheme <%s> ;\n", iris.Scheme)
+		fmt.Fprintf(&b, "    skos:prefLabel %s", turtleLiteral(c.PrefLabel, c.Lang))
+		for _, l := range c.AltLabels {
+			fmt.Fprintf(&b, " ;\n    skos:altLabel %s", turtleLiteral(l, c.Lang))
+		}
+		if c.Definition != "" {
+			fmt.Fprintf(&b, " ;\n    skos:definition %s", turtleLiteral(c.Definition, c.Lang))
+		}
+		for _, iri := range c.Broader {
+			fmt.Fprintf(&b, " ;\n    skos:broader <%s>", iri)
+		}
+		b.WriteString(" .\n")
+	}
+
+	return []byte(b.String()), nil
+}
+
+// JSONLD renders the taxonomy as a SKOS concept scheme in JSON-LD.
+func (t *Taxonomy) JSONLD(iris IRIs) ([]byte, error) {
+	ref := func(name string) map[string]string {
+		return map[string]string{"@id": "skos:" + name, "@type": "@id"}
+	}
+	literal := func(value, lang string) interface{} {
+		if lang == "" {
+			return value
+		}
+		return map[string]string{"@value": value, "@language": lang}
+	}
+
+	scheme := map[string]interface{}{
+		"@id":       iris.Scheme,
+		"@type":     "skos:ConceptScheme",
+		"prefLabel": t.Name,
+	}
+	nodes := []interface{}{scheme}
+
+	var top []string
+	for _, c := range t.concepts(iris) {
+		node := map[string]interface{}{
+			"@id":       c.IRI,
+			"@type":     "skos:Concept",
+			"inScheme":  iris.Scheme,
+			"prefLabel": literal(c.PrefLabel, c.Lang),
+		}
+		if len(c.AltLabels) > 0 {
+			var labels []interface{}
+			for _, l := range c.AltLabels {
+				labels = append(labels, literal(l, c.Lang))
+			}
+			node["altLabel"] = labels
+		}
+		if c.Definition != "" {
+			node["definition"] = literal(c.Definition, c.Lang)
+		}
+		if len(c.Broader) > 0 {
+			node["broader"] = c.Broader
+		} else {
+			top = append(top, c.IRI)
+		}
+		nodes = append(nodes, node)
+	}
+	if len(top) > 0 {
+		scheme["hasTopConcept"] = top
+	}
+
+	return json.MarshalIndent(map[string]interface{}{
+		"@context": map[string]interface{}{
+			"skos":          skosNamespace,
+			"prefLabel":     "skos:prefLabel",
+			"altLabel":      "skos:altLabel",
+			"definition":    "skos:definition",
+			"broader":       ref("broader"),
+			"inScheme":      ref("inScheme"),
+			"hasTopConcept": ref("hasTopConcept"),
+		},
+		"@graph": nodes,
+	}, "", "  ")
+}
+
+var turtleEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+func turtleLiteral(value, lang string) string {
+	literal := `"` + turtleEscaper.Replace(value) + `"`
+	if lang != "" {
+		literal += "@" + lang
+	}
+
+	return literal
+}
+
+var (
+	langTags     map[string]string
+	langTagsOnce sync.Once
+)
+
+// langTag finds the language tag of a Wiktionary language name, e.g. ru for
+// Русский, preferring the shortest code. Unknown languages yield "".
+func langTag(name string) string {
+	langTagsOnce.Do(func() {
+		codes := make([]string, 0, len(parser.Languages.Codes))
+		for code := range parser.Languages.Codes {
+			// Script variants such as az.cyr are not valid tags.
+			if !strings.ContainsAny(code, "._") {
+				codes = append(codes, code)
+			}
+		}
+		sort.Slice(codes, func(i, j int) bool {
+			if len(codes[i]) != len(codes[j]) {
+				return len(codes[i]) < len(codes[j])
+			}
+			return codes[i] < codes[j]
+		})
+
+		langTags = map[string]string{wikt.Russian: "ru"}
+		for _, code := range codes {
+			name := parser.Languages.Codes[code]
+			if _, ok := langTags[name]; !ok {
+				langTags[name] = code
+			}
+		}
+	})
+
+	return langTags[name]
+}
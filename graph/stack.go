@@ -1,32 +1,71 @@
 package graph
 
 type pair struct {
-	t, h string
-	pp   *predictedParams
+	t, h  string
+	pp    *predictedParams
+	depth int
+	down  bool
+	// rel and hops are set for pairs linked by a side relation.
+	rel  Relation
+	hops int
 }
 
-type stack []pair
+// stack holds the pairs left to check. It pops the last pushed pair
+// unless fifo is set, which turns it into a queue walking breadth first.
+type stack struct {
+	pairs []pair
+	fifo  bool
+}
 
 func (s *stack) empty() bool {
-	return len(*s) == 0
+	return len(s.pairs) == 0
 }
 
-func (s *stack) push(t string, hs []string) {
-	for i := len(hs) - 1; i >= 0; i-- {
-		*s = append(*s, pair{t: t, h: hs[i]})
-	}
+func (s *stack) push(t string, depth int, hs []string) {
+	s.add(len(hs), func(i int) pair {
+		return pair{t: t, h: hs[i], depth: depth}
+	})
+}
+
+func (s *stack) push2(t string, depth int, hs []string, pp []*predictedParams) {
+	s.add(len(hs), func(i int) pair {
+		return pair{t: t, h: hs[i], pp: pp[i], depth: depth}
+	})
+}
+
+func (s *stack) pushDown(t string, depth int, hs []string) {
+	s.add(len(hs), func(i int) pair {
+		return pair{t: t, h: hs[i], depth: depth, down: true}
+	})
+}
+
+func (s *stack) pushRelation(t string, depth int, rel Relation, hops int, hs []string) {
+	s.add(len(hs), func(i int) pair {
+		return pair{t: t, h: hs[i], depth: depth, rel: rel, hops: hops}
+	})
 }
 
-func (s *stack) push2(t string, hs []string, pp []*predictedParams) {
-	for i := len(hs) - 1; i >= 0; i-- {
-		*s = append(*s, pair{t: t, h: hs[i], pp: pp[i]})
+// add appends n pairs so that they are popped in their own order.
+func (s *stack) add(n int, at func(int) pair) {
+	for i := 0; i < n; i++ {
+		if s.fifo {
+			s.pairs = append(s.pairs, at(i))
+		} else {
+			s.pairs = append(s.pairs, at(n-1-i))
+		}
 	}
 }
 
-func (s *stack) pop() (string, string, *predictedParams) {
-	l := len(*s)
-	last := (*s)[l-1]
-	*s = (*s)[:l-1]
+func (s *stack) pop() pair {
+	if s.fifo {
+		first := s.pairs[0]
+		s.pairs = s.pairs[1:]
+		return first
+	}
+
+	l := len(s.pairs)
+	last := s.pairs[l-1]
+	s.pairs = s.pairs[:l-1]
 
-	return last.t, last.h, last.pp
+	return last
 }
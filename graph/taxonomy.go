@@ -0,0 +1,212 @@
+package graph
+
+type Kind string
+
+const (
+	Own       Kind = "own"
+	Predicted Kind = "predicted"
+	// Curated edges and nodes come from a Patch rather than Wiktionary.
+	Curated Kind = "curated"
+)
+
+type Relation string
+
+const (
+	Hyperonymy Relation = "hyperonymy"
+	Synonymy   Relation = "synonymy"
+	Antonymy   Relation = "antonymy"
+	Meronymy   Relation = "meronymy"
+	Holonymy   Relation = "holonymy"
+	// Hyponymy and Translation link the steps of a Path.
+	Hyponymy    Relation = "hyponymy"
+	Translation Relation = "translation"
+)
+
+type Node struct {
+	ID         string `json:"id"`
+	Title      string `json:"title"`
+	Lang       string `json:"lang"`
+	Sense      int    `json:"sense"`
+	Meaning    string `json:"meaning"`
+	Polysemous bool   `json:"polysemous"`
+	Root       bool   `json:"root"`
+	Depth      int    `json:"depth"`
+	// Lemmas lists every member of a synset node, Title included.
+	Lemmas []string `json:"lemmas"`
+	// Synonyms lists the synonyms Wiktionary gives for the sense.
+	Synonyms []string `json:"synonyms,omitempty"`
+	// Truncated marks nodes whose expansion was cut by Options limits.
+	Truncated bool `json:"truncated"`
+	// Confidence is the best confidence of the edges of the node, 1 for seed words.
+	Confidence float64 `json:"confidence"`
+	// Curated marks nodes added by a Patch.
+	Curated bool `json:"curated,omitempty"`
+}
+
+// Provenance records how an edge was derived.
+type Provenance struct {
+	// Tooltip is the derivation chain, e.g. кот:0->животное:1.
+	Tooltip    string `json:"tooltip"`
+	Strict     bool   `json:"strict"`
+	Polysemous bool   `json:"polysemous"`
+	// Confidence ranges from 0 to 1, see ConfidenceBackLink and the like.
+	Confidence float64 `json:"confidence"`
+	// Pivots lists the translation paths voting for a predicted edge,
+	// e.g. Английский:cat->Русский:кот:0->животное:0->animal.
+	Pivots []string `json:"pivots,omitempty"`
+}
+
+type Edge struct {
+	From     string   `json:"from"`
+	To       string   `json:"to"`
+	Relation Relation `json:"relation"`
+	Kind     Kind     `json:"kind"`
+	// Cycle marks hyperonymy edges lying on a cycle.
+	Cycle bool `json:"cycle"`
+	Provenance
+}
+
+// Taxonomy is the typed result of Build, renderers such as DOT work off it.
+type Taxonomy struct {
+	Name string `json:"name"`
+	Lang string `json:"lang"`
+	// Langs lists the languages of a taxonomy built by Align, Lang is empty then.
+	Langs     []string `json:"langs,omitempty"`
+	Nodes     []*Node  `json:"nodes"`
+	Edges     []*Edge  `json:"edges"`
+	Truncated bool     `json:"truncated"`
+	// Cycles lists the node sets of hyperonym loops found by Build,
+	// Broken the edges dropped to break them.
+	Cycles [][]string `json:"cycles"`
+	Broken []*Edge    `json:"broken"`
+	// Redundant keeps the edges dropped by Reduce.
+	Redundant []*Edge `json:"redundant"`
+	// Trace explains the decisions taken by Build.
+	Trace []*Event `json:"trace"`
+
+	nodes map[string]*Node
+	edges map[edgeKey]*Edge
+	// progress receives the events of the build, see Options.Progress.
+	progress func(*Event)
+}
+
+func NewTaxonomy(name, lang string) *Taxonomy {
+	return &Taxonomy{
+		Name:   name,
+		Lang:   lang,
+		Nodes:  []*Node{},
+		Edges:  []*Edge{},
+		Cycles: [][]string{},
+		Broken: []*Edge{},
+
+		Redundant: []*Edge{},
+		Trace:     []*Event{},
+		nodes:     make(map[string]*Node),
+		edges:     make(map[edgeKey]*Edge),
+	}
+}
+
+func (t *Taxonomy) Node(id string) *Node {
+	return t.nodes[id]
+}
+
+// edgeKey identifies an edge: the same words may be linked by several relations,
+// e.g. a word listed both as a synonym and a hyperonym.
+type edgeKey struct {
+	from, to string
+	relation Relation
+}
+
+func (t *Taxonomy) Edge(from, to string, relation Relation) *Edge {
+	return t.edges[edgeKey{from, to, relation}]
+}
+
+func (t *Taxonomy) AddNode(n *Node) {
+	t.Nodes = append(t.Nodes, n)
+	t.nodes[n.ID] = n
+}
+
+func (t *Taxonomy) AddEdge(e *Edge) {
+	t.Edges = append(t.Edges, e)
+	t.edges[edgeKey{e.From, e.To, e.Relation}] = e
+}
+
+func (t *Taxonomy) RemoveEdge(from, to string, relation Relation) {
+	key := edgeKey{from, to, relation}
+	e := t.edges[key]
+	if e == nil {
+		return
+	}
+
+	edges := t.Edges[:0]
+	for _, x := range t.Edges {
+		if x != e {
+			edges = append(edges, x)
+		}
+	}
+	t.Edges = edges
+	delete(t.edges, key)
+}
+
+// Merge folds the node from into the node into: edges are redirected,
+// loops and duplicates are dropped, lemmas are united.
+func (t *Taxonomy) Merge(into, from string) {
+	dst, src := t.nodes[into], t.nodes[from]
+	if dst == nil || src == nil || dst == src {
+		return
+	}
+
+	if len(dst.Lemmas) == 0 {
+		dst.Lemmas = []string{dst.Title}
+	}
+	for _, l := range append([]string{src.Title}, src.Lemmas...) {
+		if !contains(dst.Lemmas, l) {
+			dst.Lemmas = append(dst.Lemmas, l)
+		}
+	}
+	synonyms := append([]string(nil), dst.Synonyms...)
+	for _, s := range src.Synonyms {
+		if !contains(synonyms, s) {
+			synonyms = append(synonyms, s)
+		}
+	}
+	dst.Synonyms = synonyms
+	dst.Root = dst.Root || src.Root
+	if src.Depth < dst.Depth {
+		dst.Depth = src.Depth
+	}
+
+	nodes := t.Nodes[:0]
+	for _, n := range t.Nodes {
+		if n != src {
+			nodes = append(nodes, n)
+		}
+	}
+
+	edges := t.Edges
+	t.Nodes, t.Edges = nodes, []*Edge{}
+	t.reindex()
+	for _, e := range edges {
+		if e.From == from {
+			e.From = into
+		}
+		if e.To == from {
+			e.To = into
+		}
+		if e.From != e.To && t.Edge(e.From, e.To, e.Relation) == nil {
+			t.AddEdge(e)
+		}
+	}
+}
+
+func (t *Taxonomy) reindex() {
+	t.nodes = make(map[string]*Node, len(t.Nodes))
+	for _, n := range t.Nodes {
+		t.nodes[n.ID] = n
+	}
+
+	t.edges = make(map[edgeKey]*Edge, len(t.Edges))
+	for _, e := range t.Edges {
+		t.edges[edgeKey{e.From, e.To, e.Relation}] = e
+	}
+}
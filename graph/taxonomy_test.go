@@ -0,0 +1,30 @@
+package graph
+
+import "strings"
+
+// taxonomyOf builds a taxonomy out of edges written as from->to,
+// nodes are titled after their IDs without the sense.
+func taxonomyOf(edges ...string) *Taxonomy {
+	t := NewTaxonomy("test", "Русский")
+	for _, edge := range edges {
+		ends := strings.Split(edge, "->")
+		for _, id := range ends {
+			if t.Node(id) == nil {
+				t.AddNode(&Node{ID: id, Title: strings.Split(id, ":")[0]})
+			}
+		}
+		t.AddEdge(&Edge{From: ends[0], To: ends[1], Relation: Hyperonymy, Provenance: Provenance{Tooltip: edge}})
+	}
+
+	return t
+}
+
+// chains lists edges as from->to.
+func chains(edges []*Edge) []string {
+	list := []string{}
+	for _, e := range edges {
+		list = append(list, e.From+"->"+e.To)
+	}
+
+	return list
+}
@@ -0,0 +1,83 @@
+package graph
+
+import (
+	"fmt"
+	"log"
+)
+
+type Action string
+
+const (
+	Selected Action = "selected"
+	Denied   Action = "denied"
+	Missing  Action = "missing"
+	Limited  Action = "limited"
+	Added    Action = "added"
+	Merged   Action = "merged"
+	Dropped  Action = "dropped"
+	// Fetching and Fetched are reported to Options.Progress only.
+	Fetching Action = "fetching"
+	Fetched  Action = "fetched"
+)
+
+// Event is a decision taken while building a taxonomy.
+type Event struct {
+	// Key is the chain the decision concerns, e.g. кот->животное,
+	// the tooltip of the edge or the title of the node it results in.
+	Key string `json:"key"`
+	// Title is the word under consideration.
+	Title  string `json:"title"`
+	Action Action `json:"action"`
+	Reason string `json:"reason"`
+	// Node and Edge are the ones added by the event.
+	Node *Node `json:"node,omitempty"`
+	Edge *Edge `json:"edge,omitempty"`
+}
+
+func (e *Event) String() string {
+	return fmt.Sprintf("%s [%s]: %s", e.Key, e.Action, e.Reason)
+}
+
+// trace records an event in Trace, see record.
+func (t *Taxonomy) trace(key, title string, action Action, format string, args ...interface{}) {
+	t.record(&Event{
+		Key:    key,
+		Title:  title,
+		Action: action,
+		Reason: fmt.Sprintf(format, args...),
+	})
+}
+
+// record appends e to Trace, logs and reports it.
+func (t *Taxonomy) record(e *Event) {
+	t.Trace = append(t.Trace, e)
+	log.Println(e)
+	t.report(e)
+}
+
+// report passes a copy of e to the progress handler, which may read it
+// on another goroutine while the build goes on changing the node and edge.
+func (t *Taxonomy) report(e *Event) {
+	if t.progress != nil {
+		t.progress(e.copy())
+	}
+}
+
+func (e *Event) copy() *Event {
+	c := *e
+	if e.Node != nil {
+		n := *e.Node
+		if n.Lemmas != nil {
+			n.Lemmas = append([]string{}, n.Lemmas...)
+		}
+		n.Synonyms = append([]string(nil), n.Synonyms...)
+		c.Node = &n
+	}
+	if e.Edge != nil {
+		edge := *e.Edge
+		edge.Pivots = append([]string(nil), edge.Pivots...)
+		c.Edge = &edge
+	}
+
+	return &c
+}
@@ -4,6 +4,10 @@ import (
 	wikt "github.com/stillpiercer/wikitologies/wiktionary"
 )
 
+// Version is bumped whenever Parse extracts new data, e.g. 2 added
+// meronyms and holonyms, so that words cached by older parsers are parsed again.
+const Version = 2
+
 type Section struct {
 	Header      string
 	Text        string
@@ -55,6 +59,8 @@ type Meaning struct {
 	Antonyms     []string
 	Hyperonyms   []string
 	Hyponyms     []string
+	Holonyms     []string
+	Meronyms     []string
 	Translations Translations
 }
 
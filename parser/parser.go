@@ -144,6 +144,7 @@ func parseType1(sections Sections) Meanings {
 	values, examples := parseMeaningsSection(mSection.Text)
 	l := len(values)
 	synonyms, antonyms, hyperonyms, hyponyms := make([][]string, l), make([][]string, l), make([][]string, l), make([][]string, l)
+	holonyms, meronyms := make([][]string, l), make([][]string, l)
 
 	if sSection := sections.ByHeader(wikt.Synonyms); sSection != nil {
 		parseRelationsSection(sSection.Text, synonyms)
@@ -157,6 +158,12 @@ func parseType1(sections Sections) Meanings {
 	if hypoSection := sections.ByHeader(wikt.Hyponyms); hypoSection != nil {
 		parseRelationsSection(hypoSection.Text, hyponyms)
 	}
+	if holoSection := sections.ByHeader(wikt.Holonyms); holoSection != nil {
+		parseRelationsSection(holoSection.Text, holonyms)
+	}
+	if meroSection := sections.ByHeader(wikt.Meronyms); meroSection != nil {
+		parseRelationsSection(meroSection.Text, meronyms)
+	}
 
 	var meanings Meanings
 	for i, value := range values {
@@ -167,6 +174,8 @@ func parseType1(sections Sections) Meanings {
 			Antonyms:   antonyms[i],
 			Hyperonyms: hyperonyms[i],
 			Hyponyms:   hyponyms[i],
+			Holonyms:   holonyms[i],
+			Meronyms:   meronyms[i],
 		})
 	}
 
@@ -207,6 +216,14 @@ func parseType2(text string) Meanings {
 					for _, word := range strings.Split(values[i+1], ",") {
 						meaning.Hyponyms = append(meaning.Hyponyms, trim(word))
 					}
+				case "холонимы:":
+					for _, word := range strings.Split(values[i+1], ",") {
+						meaning.Holonyms = append(meaning.Holonyms, trim(word))
+					}
+				case "меронимы:":
+					for _, word := range strings.Split(values[i+1], ",") {
+						meaning.Meronyms = append(meaning.Meronyms, trim(word))
+					}
 				}
 			}
 		}
@@ -226,6 +243,7 @@ func parseType3(title string, mSection *Section) (Meanings, error) {
 	values, examples := parseMeaningsSection(mSection.Text)
 	l := len(values)
 	synonyms, antonyms, hyperonyms, hyponyms := make([][]string, l), make([][]string, l), make([][]string, l), make([][]string, l)
+	holonyms, meronyms := make([][]string, l), make([][]string, l)
 
 	for wikt.TemplatesRE[wikt.Brackets].MatchString(wikitext) {
 		wikitext = wikt.TemplatesRE[wikt.Brackets].ReplaceAllString(wikitext, "")
@@ -264,6 +282,10 @@ func parseType3(title string, mSection *Section) (Meanings, error) {
 				hyperonyms[i] = values
 			case "|гипонимы=":
 				hyponyms[i] = values
+			case "|холонимы=":
+				holonyms[i] = values
+			case "|меронимы=":
+				meronyms[i] = values
 			}
 		}
 	}
@@ -277,6 +299,8 @@ func parseType3(title string, mSection *Section) (Meanings, error) {
 			Antonyms:   antonyms[i],
 			Hyperonyms: hyperonyms[i],
 			Hyponyms:   hyponyms[i],
+			Holonyms:   holonyms[i],
+			Meronyms:   meronyms[i],
 		})
 	}
 
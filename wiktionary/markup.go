@@ -34,7 +34,7 @@ const (
 
 var TemplatesRE = map[string]*regexp.Regexp{
 	T2Content:      regexp.MustCompile("синонимы:|конверсивы:|антонимы:|гиперонимы:|гипонимы:|согипонимы:|холонимы:|меронимы:|управление:|время:|категории:|якорь:|язык"),
-	T3Content:      regexp.MustCompile(`\|синонимы=|\|частичные синонимы=|\|антонимы=|\|частичные антонимы=|\|гиперонимы=|\|гипонимы=`),
+	T3Content:      regexp.MustCompile(`\|синонимы=|\|частичные синонимы=|\|антонимы=|\|частичные антонимы=|\|гиперонимы=|\|гипонимы=|\|холонимы=|\|меронимы=`),
 	Brackets:       regexp.MustCompile(`\([^(]*?\)`),
 	Link:           regexp.MustCompile(`\[\[([^|[]*?)]]`),
 	Template:       regexp.MustCompile("{{[^{]*?}}"),
@@ -53,6 +53,8 @@ const (
 	Antonyms     = "Антонимы"
 	Hyperonyms   = "Гиперонимы"
 	Hyponyms     = "Гипонимы"
+	Holonyms     = "Холонимы"
+	Meronyms     = "Меронимы"
 	Translations = "Перевод"
 
 	ExampleSep     = "◆"